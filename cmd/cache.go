@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jwalton/gchalk"
+	"github.com/minepkg/minepkg/internals/commands"
+	"github.com/minepkg/minepkg/internals/instances"
+	"github.com/minepkg/minepkg/internals/utils"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the global package cache",
+}
+
+func init() {
+	gcCmd := commands.New(&cobra.Command{
+		Use:   "gc",
+		Short: "Removes cached packages that are not used by any instance",
+		Long: `Removes cached packages that are not locked by any instance in the global instances directory
+(or the package in the current directory). Removed packages are downloaded again when needed.`,
+		Args: cobra.NoArgs,
+	}, &cacheGCRunner{})
+
+	statsCmd := commands.New(&cobra.Command{
+		Use:   "stats",
+		Short: "Shows how many packages are cached and how much space they use",
+		Args:  cobra.NoArgs,
+	}, &cacheStatsRunner{})
+
+	cacheCmd.AddCommand(gcCmd.Command, statsCmd.Command)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// cacheInstance returns an instance that is only used to access the global directories
+func cacheInstance() *instances.Instance {
+	instance := instances.New()
+	instance.CacheDir = root.cacheDir
+	return instance
+}
+
+type cacheGCRunner struct{}
+
+func (c *cacheGCRunner) RunE(cmd *cobra.Command, args []string) error {
+	instance := cacheInstance()
+
+	// the package in the current directory also counts
+	extraDirs := []string{}
+	if wd, err := os.Getwd(); err == nil {
+		extraDirs = append(extraDirs, wd)
+	}
+
+	referenced, err := instance.ReferencedPackages(extraDirs...)
+	if err != nil {
+		return fmt.Errorf("could not read lockfiles of instances: %w", err)
+	}
+
+	result, err := instance.PackageCache().GC(referenced)
+	if err != nil {
+		return err
+	}
+
+	// packages from older minepkg versions are not referenced by anything anymore
+	legacy := instance.LegacyPackageCacheDir()
+	if _, err := os.Stat(legacy); err == nil {
+		fmt.Println("Removing old package cache " + gchalk.Gray(legacy))
+		if err := os.RemoveAll(legacy); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf(
+		"Removed %d packages (%s) and %d index entries\n",
+		result.RemovedBlobs,
+		utils.HumanBytes(result.FreedBytes),
+		result.RemovedEntries,
+	)
+	return nil
+}
+
+type cacheStatsRunner struct{}
+
+func (c *cacheStatsRunner) RunE(cmd *cobra.Command, args []string) error {
+	instance := cacheInstance()
+
+	stats, err := instance.PackageCache().Stats()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Package cache " + gchalk.Gray(instance.PackageCacheDir()))
+	fmt.Printf("  Packages:      %d\n", stats.Blobs)
+	fmt.Printf("  Size:          %s\n", utils.HumanBytes(stats.Size))
+	fmt.Printf("  Index entries: %d\n", stats.Entries)
+	if stats.Unindexed != 0 {
+		fmt.Printf("  Unreferenced:  %d %s\n", stats.Unindexed, gchalk.Gray("(remove with \"minepkg cache gc\")"))
+	}
+	return nil
+}
@@ -23,8 +23,17 @@ var config = map[string]configEntry{
 	"useSystemJava":       {configKindBool, "", ""},
 	"verboseLogging":      {configKindBool, "", ""},
 	"acceptMinecraftEula": {configKindBool, "", ""},
+	"offline":             {configKindBool, "never access the network", ""},
 	"init.defaultSource":  {configKindBool, "", ""},
 	"updateChannel":       {configKindString, "", ""},
+	"curseforge.apiUrl":   {configKindString, "", ""},
+	"curseforge.apiKey":   {configKindString, "", ""},
+	"maven.repositories":  {configKindString, "space separated list of maven repository URLs", ""},
+	"download.mirrors":    {configKindString, "space separated list of host=https://mirror.example.com rewrites", ""},
+	"ipfs.gateway":        {configKindString, "IPFS gateway used if all other download sources fail", ""},
+	"github.assetGlob":    {configKindString, "", ""},
+	"github.token":        {configKindString, "", ""},
+	"meta.cacheTTL":       {configKindString, "how long version lists are cached before asking the server again (eg. 30m or 6h)", ""},
 }
 
 var SubCmd = &cobra.Command{
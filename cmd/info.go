@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -73,6 +74,14 @@ func (i *infoRunner) RunE(cmd *cobra.Command, args []string) error {
 			err := enc.Encode(toEncode)
 			return err
 		}
+		if wantsLockfile {
+			if instance.Lockfile == nil {
+				return fmt.Errorf("no lockfile found. Run \"minepkg install\" to create one")
+			}
+			fmt.Println(instance.Lockfile)
+			printOverrides(instance.Lockfile)
+			return nil
+		}
 		fmt.Println(instance.Manifest)
 		return nil
 	}
@@ -166,6 +175,29 @@ func (i *infoRunner) RunE(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printOverrides lists all dependencies that were forced by the [overrides] table and what was requested originally
+func printOverrides(lockfile *manifest.Lockfile) {
+	names := make([]string, 0)
+	for name, dep := range lockfile.Dependencies {
+		if dep.Override != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+
+	fmt.Println("Forced by [overrides]:")
+	for _, name := range names {
+		dep := lockfile.Dependencies[name]
+		fmt.Printf("  %s@%s (override %q)\n", name, dep.Version, dep.Override)
+		for _, original := range dep.OriginalRequests {
+			fmt.Printf("    instead of: %s\n", original)
+		}
+	}
+}
+
 var bars = []string{
 	" ", "⢀", "⢠", "⢰", "⢸",
 	"⡀", "⣀", "⣠", "⣰", "⣸",
@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,7 +16,7 @@ import (
 	"github.com/manifoldco/promptui"
 	"github.com/minepkg/minepkg/internals/api"
 	"github.com/minepkg/minepkg/internals/commands"
-	"github.com/minepkg/minepkg/internals/downloadmgr"
+	"github.com/minepkg/minepkg/internals/launcher"
 	"github.com/minepkg/minepkg/internals/utils"
 )
 
@@ -40,11 +40,6 @@ func (i *installRunner) installFromMinepkg(mods []string) error {
 	s := spinner.New(spinner.CharSets[9], 300*time.Millisecond) // Build our new spinner
 	s.Prefix = " "
 
-	mgr := downloadmgr.New()
-	mgr.OnProgress = func(p int) {
-		s.Suffix = fmt.Sprintf(" Downloading %v", p) + "%"
-	}
-
 	// resolve requirements
 	if instance.Lockfile == nil || !instance.Lockfile.HasRequirements() {
 		s.Suffix = " Resolving Requirements"
@@ -121,6 +116,12 @@ func (i *installRunner) installFromMinepkg(mods []string) error {
 		}
 	}
 
+	for _, release := range releases {
+		if err := i.offerOptionalDependencies(release); err != nil {
+			return err
+		}
+	}
+
 	instance.UpdateLockfileDependencies(context.TODO())
 	for _, dep := range instance.Lockfile.Dependencies {
 		fmt.Printf(" - %s@%s\n", dep.Name, dep.Version)
@@ -131,20 +132,30 @@ func (i *installRunner) installFromMinepkg(mods []string) error {
 	}
 
 	task.Step("🚚", fmt.Sprintf("Downloading %d Packages", len(missingFiles)))
-	for _, m := range missingFiles {
-		p := filepath.Join(instance.PackageCacheDir(), m.Name, m.Version+m.FileExt())
-		item := downloadmgr.HTTPItem{URL: m.URL, Target: p, Sha256: m.Sha256}
-		mgr.Add(&item)
+	s.Suffix = " Downloading"
+	started := time.Now()
+	instance.DownloadProgress = func(transferred int64, total int64) {
+		rate := float64(transferred) / time.Since(started).Seconds()
+		s.Lock()
+		defer s.Unlock()
+		s.Suffix = fmt.Sprintf(
+			" Downloading %s / %s (%s/s)",
+			utils.HumanBytes(transferred),
+			utils.HumanBytes(total),
+			utils.HumanBytes(int64(rate)),
+		)
 	}
-
 	s.Start()
-	if err := mgr.Start(context.TODO()); err != nil {
+	// downloads into the package cache and links everything
+	if err := instance.EnsureDependencies(context.TODO()); err != nil {
 		logger.Fail(err.Error())
 	}
 
-	instance.LinkDependencies()
-
 	s.Stop()
+	// the launch fails if these are not resolved, so we warn early
+	if conflicts, err := instance.ModConflicts(); err == nil {
+		launcher.PrintModConflicts(instance, conflicts)
+	}
 	instance.SaveManifest()
 	instance.SaveLockfile()
 	fmt.Println("updated minepkg.toml")
@@ -153,6 +164,49 @@ func (i *installRunner) installFromMinepkg(mods []string) error {
 	return nil
 }
 
+// offerOptionalDependencies asks if the optional dependencies of the release should be added as well
+func (i *installRunner) offerOptionalDependencies(release *api.Release) error {
+	if root.NonInteractive || len(release.OptionalDependencies) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(release.OptionalDependencies))
+	for name := range release.OptionalDependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		// already installed
+		if _, ok := i.instance.Manifest.Dependencies[name]; ok {
+			continue
+		}
+		if _, ok := i.instance.Manifest.Dev.Dependencies[name]; ok {
+			continue
+		}
+
+		input := confirmation.New(
+			fmt.Sprintf("%s works better with %s (optional). Install it too?", release.Package.Name, name),
+			confirmation.No,
+		)
+		install, err := input.RunPrompt()
+		if err != nil {
+			return err
+		}
+		if !install {
+			continue
+		}
+
+		if !i.dev {
+			i.instance.Manifest.AddDependency(name, release.OptionalDependencies[name])
+		} else {
+			i.instance.Manifest.AddDevDependency(name, release.OptionalDependencies[name])
+		}
+	}
+
+	return nil
+}
+
 func searchFallback(ctx context.Context, name string) *api.Project {
 	projects, _ := root.AutoCompleter.GetProjects(ctx)
 	return _searchFallback(projects, name)
@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/minepkg/minepkg/internals/commands"
 	"github.com/minepkg/minepkg/internals/instances"
+	"github.com/minepkg/minepkg/internals/pkgid"
 	"github.com/spf13/cobra"
 )
 
@@ -54,6 +57,10 @@ func (i *installRunner) RunE(cmd *cobra.Command, args []string) error {
 		return installManifest(instance)
 	}
 
+	if root.Offline {
+		return errNeedsNetwork("install " + args[0])
+	}
+
 	firstArg := args[0]
 	if strings.HasPrefix(firstArg, "https://") {
 		switch {
@@ -62,9 +69,38 @@ func (i *installRunner) RunE(cmd *cobra.Command, args []string) error {
 			projectName := firstArg[28:] // url minus first bits (just the name)
 			return i.installFromMinepkg([]string{projectName})
 		}
+
+		// other providers might know this url (eg. github releases)
+		converted, err := root.ProviderStore.ConvertURL(context.TODO(), firstArg)
+		if err == nil {
+			return i.installFromProviderID(converted)
+		}
 		return fmt.Errorf("sorry. Don't know what to do with that url (yet)")
 	}
 
 	// fallback to minepkg
 	return i.installFromMinepkg(args)
 }
+
+// installFromProviderID adds a dependency in the provider form (eg. "github:owner/repo@v1.0.0") and installs it
+func (i *installRunner) installFromProviderID(id string) error {
+	parsed := pkgid.Parse(id)
+	// owner/repo style names are saved as repo
+	name := path.Base(parsed.Name)
+
+	logger.Info("Installing " + id + " as " + name)
+	if !i.dev {
+		i.instance.Manifest.AddDependency(name, id)
+	} else {
+		fmt.Println("Adding as dev dependency!")
+		i.instance.Manifest.AddDevDependency(name, id)
+	}
+
+	if err := installManifest(i.instance); err != nil {
+		return err
+	}
+
+	i.instance.SaveManifest()
+	fmt.Println("updated minepkg.toml")
+	return nil
+}
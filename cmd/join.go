@@ -45,6 +45,9 @@ type joinRunner struct {
 }
 
 func (j *joinRunner) RunE(cmd *cobra.Command, args []string) error {
+	if root.Offline {
+		return errNeedsNetwork("join")
+	}
 
 	var resolvedModpack *api.Release
 	ip := "127.0.0.1"
@@ -47,11 +47,12 @@ Alternatively: Can be used in directories containing a minepkg.toml manifest to
 	cmd.Flags().BoolVarP(&runner.serverMode, "server", "s", false, "Start a server instead of a client")
 	cmd.Flags().BoolVarP(&runner.forceUpdate, "update", "u", false, "Force check for updates before starting")
 	cmd.Flags().BoolVar(&runner.debugMode, "debug", false, "Do not start, just debug")
-	cmd.Flags().BoolVar(&runner.offlineMode, "offline", false, "Start the server in offline mode (server only)")
+	cmd.Flags().BoolVar(&runner.offlineMode, "offline-server", false, "Start the server in offline mode (server only). This used to be --offline")
 	cmd.Flags().BoolVar(&runner.onlyPrepare, "only-prepare", false, "Only prepare, skip launching")
 	cmd.Flags().BoolVar(&runner.crashTest, "crashtest", false, "Stop server after it's online (can be used for testing)")
 	cmd.Flags().BoolVar(&runner.noBuild, "no-build", false, "Skip build (if any)")
 	cmd.Flags().BoolVar(&runner.clean, "clean", false, "Removes any instance data except for savegames")
+	cmd.Flags().BoolVar(&runner.skipModChecks, "skip-mod-checks", false, "Launch even if the requirements in the fabric.mod.json of mods are not met or mods are installed more than once")
 	cmd.Flags().StringArrayVar(&runner.patch, "patch", runner.patch, "Apply a patch to the instance before launching")
 	runner.overwrites = launcher.CmdOverwriteFlags(cmd.Command)
 
@@ -69,6 +70,8 @@ type launchRunner struct {
 	clean       bool
 	patch       []string
 
+	skipModChecks bool
+
 	overwrites *launcher.OverwriteFlags
 
 	instance *instances.Instance
@@ -86,6 +89,7 @@ var (
 
 func (l *launchRunner) RunE(cmd *cobra.Command, args []string) error {
 	var err error
+	warnOfflineServer(l.serverMode, l.offlineMode)
 
 	vanillaManifest.Requirements.Minecraft = "*"
 	vanillaManifest.Requirements.MinepkgCompanion = "none"
@@ -104,10 +108,14 @@ func (l *launchRunner) RunE(cmd *cobra.Command, args []string) error {
 		if args[0] == "vanilla" {
 			log.Println("launching vanilla")
 			l.instance = instances.New()
+			l.instance.Offline = root.Offline
 			l.instance.Manifest = vanillaManifest
 			l.instance.Directory = filepath.Join(l.instance.InstancesDir(), "vanilla")
 
 		} else {
+			if root.Offline {
+				return errNeedsNetwork("launch " + args[0])
+			}
 			log.Println("launching online modpack")
 			l.instance, err = l.instanceFromModpack(args[0])
 		}
@@ -116,11 +124,8 @@ func (l *launchRunner) RunE(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	switch {
-	case l.crashTest && !l.serverMode:
+	if l.crashTest && !l.serverMode {
 		logger.Fail("Can only crashtest servers. append --server to crashtest")
-	case l.instance.Manifest.PlatformString() == "forge":
-		logger.Fail("Can not launch forge modpacks for now. Sorry.")
 	}
 
 	// we need login credentials to launch the client
@@ -159,6 +164,7 @@ func (l *launchRunner) RunE(cmd *cobra.Command, args []string) error {
 		MinepkgVersion: rootCmd.Version,
 		NonInteractive: viper.GetBool("nonInteractive"),
 		UseSystemJava:  viper.GetBool("useSystemJava"),
+		SkipModChecks:  l.skipModChecks,
 	}
 
 	cliLauncher.ApplyOverWrites(l.overwrites)
@@ -318,7 +324,7 @@ func (l *launchRunner) instanceFromModpack(modpack string) (*instances.Instance,
 
 	// fetch modpack
 	query := &api.ReleasesQuery{
-		Platform:     "fabric", // TODO: not static!
+		Platform:     l.overwrites.Platform(),
 		Name:         modpack,
 		VersionRange: "*", // TODO: get from id
 	}
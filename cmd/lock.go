@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+
+	"github.com/jwalton/gchalk"
+	"github.com/minepkg/minepkg/internals/commands"
+	"github.com/minepkg/minepkg/internals/instances"
+	"github.com/minepkg/minepkg/pkg/manifest"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	runner := &lockRunner{}
+	cmd := commands.New(&cobra.Command{
+		Use:   "lock",
+		Short: "Resolves all requirements & dependencies and writes the lockfile",
+		Long: `Resolves all requirements & dependencies and writes the lockfile.
+Fabric & Quilt mods are downloaded into the package cache to record the mod ids they contain.
+Use --check in CI to make sure the committed lockfile is up to date.`,
+		Args: cobra.ExactArgs(0),
+	}, runner)
+
+	cmd.Flags().BoolVar(&runner.check, "check", false, "Do not write the lockfile but fail if it would change")
+
+	rootCmd.AddCommand(cmd.Command)
+}
+
+type lockRunner struct {
+	check bool
+}
+
+func (l *lockRunner) RunE(cmd *cobra.Command, args []string) error {
+	if root.Offline {
+		return errNeedsNetwork("lock")
+	}
+
+	instance, err := root.LocalInstance()
+	if err != nil {
+		return err
+	}
+	if err := root.validateManifest(instance.Manifest); err != nil {
+		return err
+	}
+
+	committed, err := os.ReadFile(instance.LockfilePath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	var previous *manifest.Lockfile
+	if committed != nil {
+		if previous, err = instances.LockfileFromPath(instance.LockfilePath()); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	if err := instance.UpdateLockfileRequirements(ctx); err != nil {
+		return err
+	}
+	if err := instance.ResolveLockfileDependencies(ctx); err != nil {
+		return err
+	}
+
+	resolved := instance.Lockfile.Buffer().Bytes()
+	if !l.check {
+		if err := instance.SaveLockfile(); err != nil {
+			return err
+		}
+		fmt.Printf("Locked %d dependencies in %s\n", len(instance.Lockfile.Dependencies), instance.LockfilePath())
+		return nil
+	}
+
+	if string(committed) == string(resolved) {
+		fmt.Println("The lockfile is up to date")
+		return nil
+	}
+
+	if previous == nil {
+		fmt.Println(gchalk.Red("There is no lockfile"))
+	} else {
+		changes := lockfileChanges(previous, instance.Lockfile)
+		if len(changes) == 0 {
+			changes = append(changes, "formatting or metadata changed")
+		}
+		for _, change := range changes {
+			fmt.Println("  " + change)
+		}
+	}
+
+	return &commands.CliError{
+		Text: "the lockfile is not up to date",
+		Suggestions: []string{
+			fmt.Sprintf("Run %s and commit the updated lockfile", gchalk.Bold("minepkg lock")),
+		},
+	}
+}
+
+// lockfileChanges returns a human readable list of packages that differ between the lockfiles
+func lockfileChanges(previous *manifest.Lockfile, current *manifest.Lockfile) []string {
+	changes := make([]string, 0)
+
+	if previous.LockfileVersion != current.LockfileVersion {
+		changes = append(changes, fmt.Sprintf("~ lockfile format v%d → v%d", previous.LockfileVersion, current.LockfileVersion))
+	}
+	if previous.HasRequirements() && current.HasRequirements() {
+		before, after := previous.McManifestName(), current.McManifestName()
+		if before != after {
+			changes = append(changes, fmt.Sprintf("~ requirements %s → %s", before, after))
+		}
+	}
+
+	names := make(map[string]bool)
+	for name := range previous.Dependencies {
+		names[name] = true
+	}
+	for name := range current.Dependencies {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		before, after := previous.Dependencies[name], current.Dependencies[name]
+		switch {
+		case before == nil:
+			changes = append(changes, gchalk.Green(fmt.Sprintf("+ %s@%s", name, after.Version)))
+		case after == nil:
+			changes = append(changes, gchalk.Red(fmt.Sprintf("- %s@%s", name, before.Version)))
+		case before.Version != after.Version || before.Provider != after.Provider:
+			changes = append(changes, fmt.Sprintf("~ %s %s → %s", name, before.Version, after.Version))
+		}
+	}
+
+	return changes
+}
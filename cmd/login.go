@@ -63,6 +63,10 @@ func (r *Root) getLaunchCredentialsOrLogin() (*instances.LaunchCredentials, erro
 		r.restoreAuth()
 	}
 
+	if r.Offline {
+		return r.getCachedLaunchCredentials()
+	}
+
 	// still nothing, we need to login
 	if r.authProvider == nil {
 		log.Println("No auth provider found, logging in")
@@ -99,6 +103,27 @@ func (r *Root) getLaunchCredentialsOrLogin() (*instances.LaunchCredentials, erro
 	}, nil
 }
 
+// getCachedLaunchCredentials returns the stored launch credentials without contacting any auth server
+func (r *Root) getCachedLaunchCredentials() (*instances.LaunchCredentials, error) {
+	if r.authProvider == nil {
+		return nil, errNeedsNetwork("login")
+	}
+
+	creds, err := r.authProvider.CachedLaunchAuthData()
+	if err != nil {
+		return nil, fmt.Errorf("could not use stored login in offline mode: %w", err)
+	}
+
+	return &instances.LaunchCredentials{
+		PlayerName:  creds.GetPlayerName(),
+		UUID:        creds.GetUUID(),
+		AccessToken: creds.GetAccessToken(),
+		UserType:    creds.GetUserType(),
+		XUID:        creds.GetXUID(),
+		ClientID:    MS_AUTH_CLIENT_ID,
+	}, nil
+}
+
 func (r *Root) login() error {
 	r.useMicrosoftAuth()
 	err := r.authProvider.Prompt()
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/jwalton/gchalk"
 	"github.com/minepkg/minepkg/cmd/bump"
@@ -22,7 +23,9 @@ import (
 	"github.com/minepkg/minepkg/internals/cmdlog"
 	"github.com/minepkg/minepkg/internals/commands"
 	"github.com/minepkg/minepkg/internals/credentials"
+	"github.com/minepkg/minepkg/internals/downloadmgr"
 	"github.com/minepkg/minepkg/internals/instances"
+	"github.com/minepkg/minepkg/internals/metacache"
 	"github.com/minepkg/minepkg/internals/ownhttp"
 	"github.com/minepkg/minepkg/internals/provider"
 	"github.com/minepkg/minepkg/pkg/manifest"
@@ -51,8 +54,10 @@ type Root struct {
 	cacheDir           string
 	logger             *cmdlog.Logger
 	NonInteractive     bool
-	ProviderStore      *provider.Store
-	AutoCompleter      *autocomplete.AutoCompleter
+	// Offline disables all network access. Only the lockfile and cached files are used
+	Offline       bool
+	ProviderStore *provider.Store
+	AutoCompleter *autocomplete.AutoCompleter
 }
 
 func newRoot() *Root {
@@ -61,10 +66,14 @@ func newRoot() *Root {
 	minepkgClient := api.NewWithCustomHTTP(http)
 
 	providers := map[string]provider.Provider{
-		"minepkg":  &provider.MinepkgProvider{Client: minepkgClient},
-		"modrinth": provider.NewModrinthProvider(),
-		"https":    provider.NewHTTPSProvider(),
-		"dummy":    provider.NewDummyProvider(),
+		"minepkg":    &provider.MinepkgProvider{Client: minepkgClient},
+		"modrinth":   provider.NewModrinthProvider(),
+		"curseforge": provider.NewCurseForgeProvider(),
+		"maven":      provider.NewMavenProvider(),
+		"file":       instances.NewFileProvider(),
+		"github":     provider.NewGitHubProvider(),
+		"https":      provider.NewHTTPSProvider(),
+		"dummy":      provider.NewDummyProvider(),
 	}
 
 	osCacheDir, err := os.UserCacheDir()
@@ -132,12 +141,115 @@ func (r *Root) LocalInstance() (*instances.Instance, error) {
 	instance.MinepkgAPI = root.MinepkgAPI
 	instance.ProviderStore = r.ProviderStore
 	instance.CacheDir = root.cacheDir
+	instance.Offline = r.Offline
 
 	return instance, err
 }
 
+// errNeedsNetwork is returned by commands that can not work in offline mode
+func errNeedsNetwork(command string) error {
+	return &commands.CliError{
+		Text: fmt.Sprintf("minepkg %s needs network access and can not be used in offline mode", command),
+		Suggestions: []string{
+			fmt.Sprintf("Run the command again without %s", gchalk.Bold("--offline")),
+		},
+	}
+}
+
+// warnOfflineServer warns scripts that still use --offline for servers. It used to start the server
+// in offline mode (now --offline-server) but disables all network access since the global flag exists
+func warnOfflineServer(serverMode bool, offlineServer bool) {
+	if root.Offline && serverMode && !offlineServer {
+		logger.Warn("--offline disables all network access now. Use --offline-server to start the server in offline mode")
+	}
+}
+
 var logger = root.logger
 
+// configureCurseForge applies the curseforge api url & key from the global config
+func (r *Root) configureCurseForge() {
+	p, ok := r.ProviderStore.Get("curseforge")
+	if !ok {
+		return
+	}
+	curseForge := p.(*provider.CurseForgeProvider)
+
+	if apiURL := viper.GetString("curseforge.apiUrl"); apiURL != "" {
+		logger.Warn("NOT using default CurseForge API URL: " + apiURL)
+		if err := curseForge.Client.SetBaseURL(apiURL); err != nil {
+			panic(fmt.Errorf("invalid CurseForge API URL: %w", err))
+		}
+	}
+
+	curseForge.Client.APIKey = viper.GetString("curseforge.apiKey")
+	if apiKey := os.Getenv("MINEPKG_CURSEFORGE_API_KEY"); apiKey != "" {
+		curseForge.Client.APIKey = apiKey
+	}
+}
+
+// configureMaven prepends the maven repositories from the global config to the default ones
+func (r *Root) configureMaven() {
+	p, ok := r.ProviderStore.Get("maven")
+	if !ok {
+		return
+	}
+	maven := p.(*provider.MavenProvider)
+
+	if repositories := viper.GetStringSlice("maven.repositories"); len(repositories) != 0 {
+		maven.Repositories = append(repositories, provider.DefaultMavenRepositories...)
+	}
+}
+
+// configureDownloads applies the download mirrors and the IPFS gateway
+func (r *Root) configureDownloads() {
+	for _, raw := range viper.GetStringSlice("download.mirrors") {
+		mirror, err := downloadmgr.ParseHostMirror(raw)
+		if err != nil {
+			logger.Warn("Ignoring download mirror: " + err.Error())
+			continue
+		}
+		downloadmgr.HostMirrors = append(downloadmgr.HostMirrors, mirror)
+	}
+
+	if gateway := viper.GetString("ipfs.gateway"); gateway != "" {
+		instances.IPFSGateway = gateway
+	}
+}
+
+// configureMetaCache applies the TTL of cached metadata responses
+func (r *Root) configureMetaCache() {
+	raw := viper.GetString("meta.cacheTTL")
+	if raw == "" {
+		return
+	}
+	ttl, err := time.ParseDuration(raw)
+	if err != nil {
+		logger.Warn("Ignoring invalid meta.cacheTTL: " + err.Error())
+		return
+	}
+	metacache.DefaultTTL = ttl
+}
+
+// configureGitHub applies the asset glob, token and cache directory for the github provider
+func (r *Root) configureGitHub() {
+	p, ok := r.ProviderStore.Get("github")
+	if !ok {
+		return
+	}
+	gitHub := p.(*provider.GitHubProvider)
+
+	gitHub.CacheDir = filepath.Join(r.cacheDir, "github")
+
+	if glob := viper.GetString("github.assetGlob"); glob != "" {
+		gitHub.AssetGlob = glob
+	}
+
+	gitHub.Client.Token = viper.GetString("github.token")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		gitHub.Client.Token = token
+	}
+}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	// Version gets set dynamically
@@ -185,11 +297,13 @@ func initRoot() {
 	rootCmd.PersistentFlags().BoolP("accept-minecraft-eula", "a", false, "Accept Minecraft's eula. See https://www.minecraft.net/en-us/eula/")
 	rootCmd.PersistentFlags().BoolP("verbose", "", false, "More verbose logging. Not really implemented yet")
 	rootCmd.PersistentFlags().BoolP("non-interactive", "", false, "Do not prompt for anything (use defaults instead)")
+	rootCmd.PersistentFlags().BoolP("offline", "", false, "Never access the network. Only use the lockfile and cached files")
 
 	viper.BindPFlag("useSystemJava", rootCmd.PersistentFlags().Lookup("system-java"))
 	viper.BindPFlag("acceptMinecraftEula", rootCmd.PersistentFlags().Lookup("accept-minecraft-eula"))
 	viper.BindPFlag("verboseLogging", rootCmd.PersistentFlags().Lookup("verbose"))
 	viper.BindPFlag("nonInteractive", rootCmd.PersistentFlags().Lookup("non-interactive"))
+	viper.BindPFlag("offline", rootCmd.PersistentFlags().Lookup("offline"))
 	cobra.OnInitialize(initConfig)
 	// viper.SetDefault("init.defaultSource", "https://github.com/")
 
@@ -245,6 +359,12 @@ func initConfig() {
 		logger.Warn("NOT using default minepkg API URL: " + viper.GetString("apiUrl"))
 		root.MinepkgAPI.APIUrl = viper.GetString("apiUrl")
 	}
+	root.configureCurseForge()
+	root.configureMaven()
+	root.configureGitHub()
+	root.configureDownloads()
+	root.configureMetaCache()
+
 	homeConfigs, err := os.UserConfigDir()
 	if err != nil {
 		panic(err)
@@ -253,6 +373,7 @@ func initConfig() {
 	root.globalDir = filepath.Join(homeConfigs, "minepkg")
 	root.minecraftAuthStore = credentials.New(root.globalDir, "minecraft_auth")
 	root.NonInteractive = viper.GetBool("nonInteractive")
+	root.Offline = viper.GetBool("offline")
 	parsedUrl, err := url.Parse(root.MinepkgAPI.APIUrl)
 	if err != nil {
 		panic(fmt.Errorf("invalid minepkg API URL: %w", err))
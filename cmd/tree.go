@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jwalton/gchalk"
+	"github.com/minepkg/minepkg/internals/commands"
+	"github.com/minepkg/minepkg/internals/instances"
+	"github.com/minepkg/minepkg/pkg/manifest"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	runner := &treeRunner{}
+	cmd := commands.New(&cobra.Command{
+		Use:   "tree",
+		Short: "Shows the resolved dependency tree of the current package",
+		Args:  cobra.NoArgs,
+	}, runner)
+
+	cmd.Flags().BoolVar(&runner.json, "json", false, "Output json")
+
+	rootCmd.AddCommand(cmd.Command)
+}
+
+type treeRunner struct {
+	json bool
+}
+
+func (t *treeRunner) RunE(cmd *cobra.Command, args []string) error {
+	instance, err := lockedInstance()
+	if err != nil {
+		return err
+	}
+
+	tree := instance.Lockfile.Tree(rootPackageName(instance))
+	if t.json {
+		return printJSON(tree)
+	}
+
+	fmt.Println(gchalk.Bold(tree.Name))
+	printTreeNodes(tree.Dependencies, "")
+	return nil
+}
+
+// printTreeNodes prints the nodes with box drawing characters
+func printTreeNodes(nodes []*manifest.DependencyNode, indent string) {
+	for i, node := range nodes {
+		branch, nextIndent := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, nextIndent = "└── ", "    "
+		}
+
+		line := fmt.Sprintf("%s@%s %s", node.Name, node.Version, gchalk.Gray("("+node.Provider+")"))
+		if node.Cycle {
+			line += gchalk.Yellow(" (circular)")
+		}
+		fmt.Println(indent + branch + line)
+		printTreeNodes(node.Dependencies, indent+nextIndent)
+	}
+}
+
+// lockedInstance returns the local instance. It fails if the dependencies were not resolved yet
+func lockedInstance() (*instances.Instance, error) {
+	instance, err := root.LocalInstance()
+	if err != nil {
+		return nil, err
+	}
+
+	if instance.Lockfile == nil || len(instance.Lockfile.Dependencies) == 0 {
+		return nil, &commands.CliError{
+			Text: "no resolved dependencies found in the lockfile",
+			Suggestions: []string{
+				fmt.Sprintf("Run %s first", gchalk.Bold("minepkg install")),
+			},
+		}
+	}
+
+	return instance, nil
+}
+
+// rootPackageName returns the name of the instance package as used in the lockfile
+func rootPackageName(instance *instances.Instance) string {
+	if instance.Manifest.Package.Name == "" {
+		return "_root"
+	}
+	return instance.Manifest.Package.Name
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
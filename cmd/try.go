@@ -40,7 +40,7 @@ It will be deleted after testing.
 
 	cmd.Flags().StringVarP(&runner.tryBase, "base", "b", "test-mansion", "Base modpack to use for testing")
 	cmd.Flags().BoolVarP(&runner.serverMode, "server", "s", false, "Start a server instead of a client")
-	cmd.Flags().BoolVarP(&runner.offlineMode, "offline", "", false, "Start the server in offline mode (server only)")
+	cmd.Flags().BoolVarP(&runner.offlineMode, "offline-server", "", false, "Start the server in offline mode (server only). This used to be --offline")
 	cmd.Flags().BoolVarP(&runner.plain, "plain", "p", false, "Do not include default mods for testing")
 	cmd.Flags().BoolVarP(&runner.photoSession, "photosession", "", false, "Upload all screenshots (take with F2) to the project")
 
@@ -60,6 +60,10 @@ type tryRunner struct {
 }
 
 func (t *tryRunner) RunE(cmd *cobra.Command, args []string) error {
+	warnOfflineServer(t.serverMode, t.offlineMode)
+	if root.Offline {
+		return errNeedsNetwork("try")
+	}
 	apiClient := root.MinepkgAPI
 	nonInteractive := viper.GetBool("nonInteractive")
 
@@ -97,7 +101,7 @@ func (t *tryRunner) RunE(cmd *cobra.Command, args []string) error {
 	}
 
 	release, err := apiClient.ReleasesQuery(context.TODO(), &api.ReleasesQuery{
-		Platform:     "fabric", // TODO!!!
+		Platform:     t.overwrites.Platform(),
 		Name:         name,
 		Minecraft:    mcVersion,
 		VersionRange: version,
@@ -115,7 +119,7 @@ func (t *tryRunner) RunE(cmd *cobra.Command, args []string) error {
 		}
 
 		release, err = apiClient.ReleasesQuery(context.TODO(), &api.ReleasesQuery{
-			Platform:     "fabric", // TODO!!!
+			Platform:     t.overwrites.Platform(),
 			Name:         project.Name,
 			Minecraft:    mcVersion,
 			VersionRange: version,
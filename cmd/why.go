@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jwalton/gchalk"
+	"github.com/minepkg/minepkg/internals/commands"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	runner := &whyRunner{}
+	cmd := commands.New(&cobra.Command{
+		Use:   "why <package>",
+		Short: "Shows why a package is installed (every path from your package to it)",
+		Args:  cobra.ExactArgs(1),
+	}, runner)
+
+	cmd.Flags().BoolVar(&runner.json, "json", false, "Output json")
+
+	rootCmd.AddCommand(cmd.Command)
+}
+
+type whyRunner struct {
+	json bool
+}
+
+func (w *whyRunner) RunE(cmd *cobra.Command, args []string) error {
+	instance, err := lockedInstance()
+	if err != nil {
+		return err
+	}
+
+	name := args[0]
+	lockfile := instance.Lockfile
+	if _, ok := lockfile.Dependencies[name]; !ok {
+		return &commands.CliError{
+			Text: fmt.Sprintf("%s is not a resolved dependency", name),
+			Suggestions: []string{
+				fmt.Sprintf("Use %s to list all resolved dependencies", gchalk.Bold("minepkg tree")),
+			},
+		}
+	}
+
+	paths := lockfile.PathsTo(rootPackageName(instance), name)
+	if w.json {
+		return printJSON(paths)
+	}
+
+	dep := lockfile.Dependencies[name]
+	fmt.Printf("%s@%s is required by:\n", name, dep.Version)
+	for _, path := range paths {
+		parts := make([]string, len(path))
+		for i, p := range path {
+			parts[i] = p
+			if lock, ok := lockfile.Dependencies[p]; ok {
+				parts[i] = p + "@" + lock.Version
+			}
+		}
+		fmt.Println("  " + strings.Join(parts, gchalk.Gray(" → ")))
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"sort"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/minepkg/minepkg/internals/mcversion"
 )
 
 // RequirementQuery is a query for a release describing contained requirements
@@ -49,10 +50,10 @@ func (e *ErrNoMatchingRelease) Error() string {
 func (m *MinepkgClient) FindRelease(ctx context.Context, project string, reqs *RequirementQuery) (*Release, error) {
 	p := Project{client: m, Name: project}
 
-	var wantedMCSemver *semver.Version
+	var wantedMCVersion *mcversion.Version
 	if reqs.Minecraft != "*" {
 		var err error
-		wantedMCSemver, err = semver.NewVersion(reqs.Minecraft)
+		wantedMCVersion, err = mcversion.Parse(reqs.Minecraft)
 		if err != nil {
 			return nil, ErrInvalidMinecraftRequirement
 		}
@@ -77,7 +78,7 @@ func (m *MinepkgClient) FindRelease(ctx context.Context, project string, reqs *R
 
 	// find all tested & working releases
 	for _, release := range releases {
-		if release.testedFor(wantedMCSemver) {
+		if release.testedFor(wantedMCVersion) {
 			testedReleases = append(testedReleases, release)
 		}
 	}
@@ -96,7 +97,7 @@ func (m *MinepkgClient) FindRelease(ctx context.Context, project string, reqs *R
 
 		// get the latest version that matches the wanted minecraft version
 		for _, release := range releases {
-			if release.isCompatible(wantedMCSemver) {
+			if release.isCompatible(wantedMCVersion) {
 				return release, nil
 			}
 		}
@@ -121,8 +122,8 @@ func (m *MinepkgClient) FindRelease(ctx context.Context, project string, reqs *R
 
 	// fallback to search all releases
 	for _, release := range releases {
-		mcCompatible := release.isCompatible(wantedMCSemver)
-		versionCompatible := release.isCompatible(wantedMCSemver)
+		mcCompatible := release.isCompatible(wantedMCVersion)
+		versionCompatible := release.isCompatible(wantedMCVersion)
 
 		if mcCompatible && versionCompatible {
 			return release, nil
@@ -155,7 +156,7 @@ func (m *MinepkgClient) FindRelease(ctx context.Context, project string, reqs *R
 }
 
 // testedFor returns true if this release was tested worked for the given minecraft version
-func (r *Release) testedFor(mcVersion *semver.Version) bool {
+func (r *Release) testedFor(mcVersion *mcversion.Version) bool {
 
 	// precondition (release requirement is compatible) failed
 	if !r.isCompatible(mcVersion) {
@@ -171,8 +172,8 @@ func (r *Release) testedFor(mcVersion *semver.Version) bool {
 }
 
 // isCompatible returns true if this release requirement is compatible with the given minecraft version
-func (r *Release) isCompatible(mcVersion *semver.Version) bool {
-	modMcConstraint, err := semver.NewConstraint(r.Requirements.Minecraft)
+func (r *Release) isCompatible(mcVersion *mcversion.Version) bool {
+	modMcConstraint, err := mcversion.NewConstraint(r.Requirements.Minecraft)
 	if err != nil {
 		// TODO: maybe this should be an error
 		fmt.Printf(
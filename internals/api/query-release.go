@@ -8,7 +8,7 @@ import (
 	"net/http"
 	"net/url"
 
-	"github.com/Masterminds/semver/v3"
+	"github.com/minepkg/minepkg/internals/mcversion"
 )
 
 // ErrNoMatchingRelease is returned if a wanted package query could not be resolved
@@ -57,7 +57,7 @@ func (m *MinepkgClient) ReleasesQuery(ctx context.Context, query *ReleasesQuery)
 	}
 	if query.Minecraft != "" {
 		var err error
-		if _, err = semver.NewVersion(query.Minecraft); err != nil {
+		if _, err = mcversion.Parse(query.Minecraft); err != nil {
 			return nil, ErrInvalidMinecraftRequirement
 		}
 	}
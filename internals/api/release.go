@@ -5,9 +5,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"sort"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/minepkg/minepkg/internals/mcversion"
 	"github.com/minepkg/minepkg/pkg/manifest"
 )
 
@@ -42,32 +42,41 @@ func (r *Release) SemverVersion() *semver.Version {
 // LatestTestedMinecraftVersion returns the last (highest) tested Minecraft version for this release
 func (r *Release) LatestTestedMinecraftVersion() string {
 
-	workingMcVersion := semver.Collection{}
+	var latest *mcversion.Version
 	// check all tests of this release for matching mc version that works
 	for _, test := range r.Tests {
-		if test.Works {
-			workingMcVersion = append(workingMcVersion, semver.MustParse(test.Minecraft))
+		if !test.Works {
+			continue
+		}
+		tested, err := mcversion.Parse(test.Minecraft)
+		if err != nil {
+			continue
+		}
+		if latest == nil || tested.GreaterThan(latest) {
+			latest = tested
 		}
 	}
 
-	sort.Sort(workingMcVersion)
 	// oh well ...
 	// TODO: maybe not static
-	if len(workingMcVersion) == 0 {
+	if latest == nil {
 		return "1.17.1"
 	}
-	return workingMcVersion[len(workingMcVersion)-1].String()
+	return latest.String()
 }
 
 // WorksWithManifest returns if this release was tested to the manifest requirements
 // (currently only checks mc version)
 func (r *Release) WorksWithManifest(man *manifest.Manifest) bool {
-	mcConstraint, err := semver.NewConstraint(man.Requirements.Minecraft)
+	mcConstraint, err := mcversion.NewConstraint(man.Requirements.Minecraft)
 	if err != nil {
 		return false
 	}
 	for _, test := range r.Tests {
-		mcVersion := semver.MustParse(test.Minecraft)
+		mcVersion, err := mcversion.Parse(test.Minecraft)
+		if err != nil {
+			continue
+		}
 		if mcConstraint.Check(mcVersion) && test.Works {
 			return true
 		}
@@ -3,7 +3,7 @@ package api
 import (
 	"time"
 
-	"github.com/Masterminds/semver/v3"
+	"github.com/minepkg/minepkg/internals/mcversion"
 	"github.com/minepkg/minepkg/pkg/manifest"
 )
 
@@ -93,8 +93,12 @@ type ReleaseTest struct {
 	Works     bool   `json:"works"`
 }
 
-func (rt *ReleaseTest) worksWithMCVersion(mcVersion *semver.Version) bool {
-	return rt.Works && (mcVersion == nil || mcVersion.Equal(mcVersion))
+func (rt *ReleaseTest) worksWithMCVersion(mcVersion *mcversion.Version) bool {
+	if !rt.Works || mcVersion == nil {
+		return rt.Works
+	}
+	tested, err := mcversion.Parse(rt.Minecraft)
+	return err == nil && tested.Compare(mcVersion) == 0
 }
 
 // Requirements contains the wanted Minecraft version
@@ -2,10 +2,14 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 
 	"github.com/minepkg/minepkg/internals/minecraft"
 )
 
+// ErrNoCachedAuthData is returned if there is no stored auth data
+var ErrNoCachedAuthData = errors.New("no stored login found")
+
 type AuthProvider interface {
 	// Name returns the name of the auth provider
 	Name() string
@@ -13,6 +17,8 @@ type AuthProvider interface {
 	Prompt() error
 	// LaunchAuthData returns the auth data needed to launch the game
 	LaunchAuthData() (minecraft.LaunchAuthData, error)
+	// CachedLaunchAuthData returns the stored auth data without refreshing it (used in offline mode)
+	CachedLaunchAuthData() (minecraft.LaunchAuthData, error)
 }
 
 type PersistentCredentials struct {
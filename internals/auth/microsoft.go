@@ -84,6 +84,16 @@ func (m *Microsoft) LaunchAuthData() (minecraft.LaunchAuthData, error) {
 	return m.authData, nil
 }
 
+func (m *Microsoft) CachedLaunchAuthData() (minecraft.LaunchAuthData, error) {
+	if m.authData == nil {
+		return nil, ErrNoCachedAuthData
+	}
+	if m.authData.IsExpired() {
+		log.Println("Using expired MS auth data (offline)")
+	}
+	return m.authData, nil
+}
+
 func (m *Microsoft) refreshAuthData() (*microsoft.Credentials, error) {
 	creds, err := m.GetMinecraftCredentials(context.Background())
 	if err != nil {
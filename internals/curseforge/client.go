@@ -0,0 +1,106 @@
+package curseforge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const DefaultApiURL = "https://api.curseforge.com/"
+
+// GameIDMinecraft is the curseforge game id of Minecraft
+const GameIDMinecraft = 432
+
+var (
+	// An error that is returned if the provided mod ID or slug is invalid
+	// currently this is only returned if it was an empty string
+	ErrInvalidModIDOrSlug = errors.New("invalid mod ID or slug")
+	// A generic error that is returned if a resource was not found
+	// Some methods return more specific errors that wrap this error (e.g. ErrModNotFound)
+	ErrResourceNotFound = errors.New("resource not found")
+	// An error that is returned if the api rejected the api key
+	ErrUnauthorized = errors.New("curseforge api key missing or invalid")
+)
+
+type Client struct {
+	http    *http.Client
+	baseURL *url.URL
+	// APIKey is sent as the `x-api-key` header with every request
+	APIKey string
+}
+
+func New(httpClient *http.Client) *Client {
+	parsedDefaultURL, _ := url.Parse(DefaultApiURL)
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		http:    httpClient,
+		baseURL: parsedDefaultURL,
+	}
+}
+
+// SetBaseURL changes the api url used by this client (eg. for testing against a local server)
+func (c *Client) SetBaseURL(baseURL string) error {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return err
+	}
+	c.baseURL = parsed
+	return nil
+}
+
+// url joins the addedPath to the baseURL (panics if new path can not be parsed)
+func (c *Client) url(addedPath ...string) *url.URL {
+	joined, err := url.JoinPath(c.baseURL.String(), addedPath...)
+	if err != nil {
+		panic(err)
+	}
+
+	url, err := url.Parse(joined)
+	if err != nil {
+		panic(err)
+	}
+
+	return url
+}
+
+// get is just a wrapper around http.Get() with context support that also sets the api key
+func (c *Client) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("x-api-key", c.APIKey)
+	}
+
+	return c.http.Do(req)
+}
+
+// decode is a helper that decodes json, and checks the status code
+func decode(res *http.Response, v interface{}) error {
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		switch res.StatusCode {
+		case 401, 403:
+			return ErrUnauthorized
+		case 404:
+			return ErrResourceNotFound
+		default:
+			return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+		}
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package curseforge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// Is returned when a mod is not found
+	ErrModNotFound = errors.Wrap(ErrResourceNotFound, "mod not found")
+	// Is returned when a file is not found
+	ErrFileNotFound = errors.Wrap(ErrResourceNotFound, "file not found")
+)
+
+// GetMod returns the mod with the given numeric ID
+func (c *Client) GetMod(ctx context.Context, id int) (*Mod, error) {
+	reqUrl := c.url("v1/mods", strconv.Itoa(id))
+	res, err := c.get(ctx, reqUrl.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data Mod `json:"data"`
+	}
+	if err = decode(res, &result); err != nil {
+		if err == ErrResourceNotFound {
+			return nil, ErrModNotFound
+		}
+		return nil, err
+	}
+
+	return &result.Data, nil
+}
+
+// GetModBySlug returns the Minecraft mod with the given slug (eg. "jei")
+func (c *Client) GetModBySlug(ctx context.Context, slug string) (*Mod, error) {
+	if slug == "" {
+		return nil, ErrInvalidModIDOrSlug
+	}
+
+	reqUrl := c.url("v1/mods/search")
+	query := url.Values{}
+	query.Set("gameId", strconv.Itoa(GameIDMinecraft))
+	query.Set("slug", slug)
+	reqUrl.RawQuery = query.Encode()
+
+	res, err := c.get(ctx, reqUrl.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []Mod `json:"data"`
+	}
+	if err = decode(res, &result); err != nil {
+		return nil, err
+	}
+
+	// the search also returns modpacks, resource packs etc. with the same slug
+	// so we prefer an exact match that is a mod (class id 6)
+	var found *Mod
+	for i, mod := range result.Data {
+		if mod.Slug != slug {
+			continue
+		}
+		if found == nil || mod.ClassID == 6 {
+			found = &result.Data[i]
+		}
+	}
+
+	if found == nil {
+		return nil, ErrModNotFound
+	}
+
+	return found, nil
+}
+
+// GetModByIDOrSlug returns the mod with the given numeric ID or slug
+func (c *Client) GetModByIDOrSlug(ctx context.Context, idOrSlug string) (*Mod, error) {
+	if id, err := strconv.Atoi(idOrSlug); err == nil {
+		return c.GetMod(ctx, id)
+	}
+	return c.GetModBySlug(ctx, idOrSlug)
+}
+
+// ListModFilesQuery is used to filter the results of ListModFiles
+type ListModFilesQuery struct {
+	// GameVersion filters by Minecraft version (e.g. "1.20.1")
+	GameVersion string
+	// ModLoaderType filters by mod loader. Pass ModLoaderAny to not filter
+	ModLoaderType ModLoaderType
+}
+
+// String converts this to a query string
+func (l *ListModFilesQuery) String() string {
+	values := url.Values{}
+	if l.GameVersion != "" {
+		values.Set("gameVersion", l.GameVersion)
+	}
+	if l.ModLoaderType != ModLoaderAny {
+		values.Set("modLoaderType", strconv.Itoa(int(l.ModLoaderType)))
+	}
+	return values.Encode()
+}
+
+// ListModFiles returns the files of a mod, newest first.
+// `query` can be used to pre filter the results. Pass nil to not filter.
+func (c *Client) ListModFiles(ctx context.Context, modID int, query *ListModFilesQuery) ([]File, error) {
+	reqUrl := c.url("v1/mods", strconv.Itoa(modID), "files")
+	if query != nil {
+		reqUrl.RawQuery = query.String()
+	}
+
+	res, err := c.get(ctx, reqUrl.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data       []File     `json:"data"`
+		Pagination Pagination `json:"pagination"`
+	}
+	if err = decode(res, &result); err != nil {
+		if err == ErrResourceNotFound {
+			return nil, ErrModNotFound
+		}
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// GetModFile returns a single file of a mod
+func (c *Client) GetModFile(ctx context.Context, modID int, fileID int) (*File, error) {
+	reqUrl := c.url("v1/mods", strconv.Itoa(modID), "files", strconv.Itoa(fileID))
+	res, err := c.get(ctx, reqUrl.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data File `json:"data"`
+	}
+	if err = decode(res, &result); err != nil {
+		if err == ErrResourceNotFound {
+			return nil, ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	if result.Data.ModID != modID {
+		return nil, fmt.Errorf("file %d does not belong to mod %d", fileID, modID)
+	}
+
+	return &result.Data, nil
+}
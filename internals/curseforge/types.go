@@ -0,0 +1,90 @@
+package curseforge
+
+import "time"
+
+// ModLoaderType is the curseforge id of a mod loader
+type ModLoaderType int
+
+const (
+	ModLoaderAny      ModLoaderType = 0
+	ModLoaderForge    ModLoaderType = 1
+	ModLoaderFabric   ModLoaderType = 4
+	ModLoaderQuilt    ModLoaderType = 5
+	ModLoaderNeoForge ModLoaderType = 6
+)
+
+// RelationType describes how a file depends on another mod
+type RelationType int
+
+const (
+	RelationEmbeddedLibrary    RelationType = 1
+	RelationOptionalDependency RelationType = 2
+	RelationRequiredDependency RelationType = 3
+	RelationTool               RelationType = 4
+	RelationIncompatible       RelationType = 5
+	RelationInclude            RelationType = 6
+)
+
+// HashAlgo is the algorithm of a file hash
+type HashAlgo int
+
+const (
+	HashAlgoSha1 HashAlgo = 1
+	HashAlgoMd5  HashAlgo = 2
+)
+
+type FileHash struct {
+	Value string   `json:"value"`
+	Algo  HashAlgo `json:"algo"`
+}
+
+type FileDependency struct {
+	ModID        int          `json:"modId"`
+	RelationType RelationType `json:"relationType"`
+}
+
+type File struct {
+	ID           int              `json:"id"`
+	ModID        int              `json:"modId"`
+	DisplayName  string           `json:"displayName"`
+	FileName     string           `json:"fileName"`
+	ReleaseType  int              `json:"releaseType"`
+	FileDate     time.Time        `json:"fileDate"`
+	FileLength   int64            `json:"fileLength"`
+	DownloadURL  string           `json:"downloadUrl"`
+	GameVersions []string         `json:"gameVersions"`
+	Hashes       []FileHash       `json:"hashes"`
+	Dependencies []FileDependency `json:"dependencies"`
+	IsAvailable  bool             `json:"isAvailable"`
+}
+
+// Sha1 returns the sha1 hash of this file or "" if it is unknown
+func (f *File) Sha1() string {
+	for _, hash := range f.Hashes {
+		if hash.Algo == HashAlgoSha1 {
+			return hash.Value
+		}
+	}
+	return ""
+}
+
+type Mod struct {
+	ID     int    `json:"id"`
+	GameID int    `json:"gameId"`
+	Name   string `json:"name"`
+	Slug   string `json:"slug"`
+	Links  struct {
+		WebsiteURL string `json:"websiteUrl"`
+	} `json:"links"`
+	Summary       string    `json:"summary"`
+	ClassID       int       `json:"classId"`
+	DateModified  time.Time `json:"dateModified"`
+	DownloadCount float64   `json:"downloadCount"`
+}
+
+type Pagination struct {
+	Index       int `json:"index"`
+	PageSize    int `json:"pageSize"`
+	ResultCount int `json:"resultCount"`
+	TotalCount  int `json:"totalCount"`
+}
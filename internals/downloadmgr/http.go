@@ -2,13 +2,19 @@ package downloadmgr
 
 import (
 	"context"
+	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -25,44 +31,107 @@ var defaultClient = http.Client{
 }
 
 // HTTPItem is a URL, target pair with optional properties that will be downloaded
-// using http(s)
+// using http(s). Every set hash is verified before the file is moved to the target.
+// Interrupted downloads are kept as "<target>.part" and resumed if the server supports it
 type HTTPItem struct {
-	Client           *http.Client
-	URL              string
+	Client *http.Client
+	URL    string
+	// Mirrors are tried in order if the download from URL fails
+	Mirrors          []string
 	Target           string
 	Size             int
+	Sha1             string
 	Sha256           string
+	Sha512           string
 	bytesTransferred int64
+	bytesTotal       int64
 }
 
-// ErrInvalidSha is returned when the downloaded file's sha256 sum does not match the given sha1
+// ErrInvalidSha is returned when a hash of the downloaded file does not match the expected one
 type ErrInvalidSha struct {
-	FileName    string
+	FileName string
+	// Algorithm is the hash that did not match (sha1, sha256 or sha512)
+	Algorithm   string
 	ExpectedSha string
 	ActualSha   string
 }
 
 func (e *ErrInvalidSha) Error() string {
 	return fmt.Sprintf(
-		"File corrupted: %s sha256 is invalid.\n\texpected to be \"%s\"\n\tbut actually is \"%s\"\n",
+		"File corrupted: %s %s is invalid.\n\texpected to be \"%s\"\n\tbut actually is \"%s\"\n",
 		e.FileName,
+		e.Algorithm,
 		e.ExpectedSha,
 		e.ActualSha,
 	)
 }
 
-// Download downloads the item to the defined target using http
+// partPath is where the file is downloaded to before it is verified
+func (i *HTTPItem) partPath() string {
+	return i.Target + ".part"
+}
+
+// validatorPath contains the ETag or Last-Modified header of the partial download.
+// It is sent as If-Range, so a changed file is never resumed
+func (i *HTTPItem) validatorPath() string {
+	return i.Target + ".part.validator"
+}
+
+// Progress returns the downloaded and the total number of bytes.
+// total is 0 if it is not known (yet)
+func (i *HTTPItem) Progress() (transferred int64, total int64) {
+	total = atomic.LoadInt64(&i.bytesTotal)
+	if total == 0 && i.Size != 0 {
+		total = int64(i.Size)
+	}
+	return atomic.LoadInt64(&i.bytesTransferred), total
+}
+
+// Sources returns all URLs this item can be downloaded from in the order they are tried.
+// Configured HostMirrors come before the URL they mirror
+func (i *HTTPItem) Sources() []string {
+	return withMirrors(append([]string{i.URL}, i.Mirrors...))
+}
+
+// Download downloads the item to the defined target using http.
+// The file is written to "<target>.part" first and only moved to the target after it was verified.
+// Every source is tried until one succeeds
 func (i *HTTPItem) Download(ctx context.Context) error {
 	err := os.MkdirAll(filepath.Dir(i.Target), os.ModePerm)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", i.URL, nil)
+	var errs []error
+	for _, source := range i.Sources() {
+		err := i.downloadFrom(ctx, source)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// downloadFrom downloads the item from a single source
+func (i *HTTPItem) downloadFrom(ctx context.Context, source string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", source, nil)
 	if err != nil {
 		return err
 	}
 
+	// try to resume a previous download
+	var offset int64
+	validator, _ := os.ReadFile(i.validatorPath())
+	if stat, err := os.Stat(i.partPath()); err == nil && len(validator) != 0 {
+		offset = stat.Size()
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", string(validator))
+	}
+
 	client := i.Client
 	if client == nil {
 		client = &defaultClient
@@ -70,38 +139,141 @@ func (i *HTTPItem) Download(ctx context.Context) error {
 
 	fileRes, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("Error while fetching %s: %w", i.URL, err)
+		return fmt.Errorf("Error while fetching %s: %w", source, err)
 	}
 	defer fileRes.Body.Close()
 
-	if fileRes.StatusCode != 200 {
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case fileRes.StatusCode == http.StatusPartialContent && offset != 0 && contentRangeStart(fileRes) == offset:
+		flags |= os.O_APPEND
+	case fileRes.StatusCode == http.StatusOK:
+		// full response, the server does not support ranges or the file changed
+		offset = 0
+		flags |= os.O_TRUNC
+		i.saveValidator(fileRes)
+	default:
+		// the partial file is useless now (eg. 416 range not satisfiable)
+		i.removePart()
 		return fmt.Errorf("invalid status code: %s from %s", fileRes.Status, fileRes.Request.URL)
 	}
 
-	dest, err := os.Create(i.Target)
+	atomic.StoreInt64(&i.bytesTransferred, offset)
+	if fileRes.ContentLength > 0 {
+		atomic.StoreInt64(&i.bytesTotal, offset+fileRes.ContentLength)
+	}
+
+	dest, err := os.OpenFile(i.partPath(), flags, 0644)
 	if err != nil {
 		return err
 	}
 	defer dest.Close()
 
-	src := io.TeeReader(fileRes.Body, &WriteCounter{&i.bytesTransferred})
-	_, err = io.Copy(dest, src)
-	if err != nil {
+	if _, err = io.Copy(dest, io.TeeReader(fileRes.Body, &WriteCounter{&i.bytesTransferred})); err != nil {
+		// the part file is kept, the next attempt resumes from here
 		return err
 	}
 	if err := dest.Sync(); err != nil {
 		return err
 	}
+	if err := dest.Close(); err != nil {
+		return err
+	}
 
-	// check sha if there is one set
-	if i.Sha256 != "" {
-		if err := checkSha256(i.Sha256, dest.Name()); err != nil {
-			return err
+	if err := i.verify(); err != nil {
+		i.removePart()
+		return err
+	}
+
+	os.Remove(i.validatorPath())
+	return os.Rename(i.partPath(), i.Target)
+}
+
+// verify checks all set hashes of the downloaded part file
+func (i *HTTPItem) verify() error {
+	hashes := i.hashes()
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	f, err := os.Open(i.partPath())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	writers := make([]io.Writer, len(hashes))
+	for n, h := range hashes {
+		writers[n] = h.hasher
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), f); err != nil {
+		return err
+	}
+
+	for _, h := range hashes {
+		actual := fmt.Sprintf("%x", h.hasher.Sum(nil))
+		if !strings.EqualFold(actual, h.expected) {
+			return &ErrInvalidSha{i.Target, h.algorithm, h.expected, actual}
 		}
 	}
 	return nil
 }
 
+// saveValidator remembers the ETag (or Last-Modified date) if the server supports range requests
+func (i *HTTPItem) saveValidator(res *http.Response) {
+	validator := res.Header.Get("ETag")
+	// weak etags can not be used with If-Range
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = res.Header.Get("Last-Modified")
+	}
+
+	if res.Header.Get("Accept-Ranges") != "bytes" || validator == "" {
+		os.Remove(i.validatorPath())
+		return
+	}
+	os.WriteFile(i.validatorPath(), []byte(validator), 0644)
+}
+
+func (i *HTTPItem) removePart() {
+	os.Remove(i.partPath())
+	os.Remove(i.validatorPath())
+}
+
+// contentRangeStart returns the first byte of a "Content-Range: bytes 100-200/300" header or -1
+func contentRangeStart(res *http.Response) int64 {
+	var start, end, size int64
+	contentRange := res.Header.Get("Content-Range")
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &size); err != nil {
+		// size can be unknown ("*")
+		if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/*", &start, &end); err != nil {
+			return -1
+		}
+	}
+	return start
+}
+
+// expectedHash is a hash that the downloaded file has to match
+type expectedHash struct {
+	algorithm string
+	expected  string
+	hasher    hash.Hash
+}
+
+// hashes returns all hashes that are set for this item
+func (i *HTTPItem) hashes() []*expectedHash {
+	hashes := make([]*expectedHash, 0, 3)
+	if i.Sha1 != "" {
+		hashes = append(hashes, &expectedHash{"sha1", i.Sha1, sha1.New()})
+	}
+	if i.Sha256 != "" {
+		hashes = append(hashes, &expectedHash{"sha256", i.Sha256, sha256.New()})
+	}
+	if i.Sha512 != "" {
+		hashes = append(hashes, &expectedHash{"sha512", i.Sha512, sha512.New()})
+	}
+	return hashes
+}
+
 // NewHTTPItem creates a Item to be queued that will download the file using HTTP(S)
 func NewHTTPItem(URL string, Target string) *HTTPItem {
 	if URL == "" {
@@ -110,28 +282,7 @@ func NewHTTPItem(URL string, Target string) *HTTPItem {
 	if Target == "" {
 		panic("Target can not be empty")
 	}
-	return &HTTPItem{&defaultClient, URL, Target, 0, "", 0}
-}
-
-func checkSha256(sha string, srcPath string) error {
-	src, err := os.Open(srcPath)
-	if err != nil {
-		return err
-	}
-	defer src.Close()
-	hasher := sha256.New()
-	_, err = io.Copy(hasher, src)
-	// probably io error during hashing
-	if err != nil {
-		return err
-	}
-	actualSha := fmt.Sprintf("%x", hasher.Sum(nil))
-	if actualSha != sha {
-		// TODO: this can fail! move file to tmp storage first
-		os.Remove(src.Name())
-		return &ErrInvalidSha{src.Name(), sha, actualSha}
-	}
-	return nil
+	return &HTTPItem{Client: &defaultClient, URL: URL, Target: Target}
 }
 
 // WriteCounter counts the number of bytes written to it.
@@ -144,6 +295,6 @@ type WriteCounter struct {
 // Always completes and never returns an error.
 func (wc *WriteCounter) Write(p []byte) (int, error) {
 	n := len(p)
-	*wc.Total += int64(n)
+	atomic.AddInt64(wc.Total, int64(n))
 	return n, nil
 }
@@ -0,0 +1,143 @@
+package downloadmgr
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHTTPItem_Download(t *testing.T) {
+	content := []byte("some jar content")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "mod.jar")
+
+	item := NewHTTPItem(server.URL, target)
+	item.Sha1 = fmt.Sprintf("%x", sha1.Sum(content))
+	item.Sha512 = fmt.Sprintf("%x", sha512.Sum512(content))
+	if err := item.Download(context.Background()); err != nil {
+		t.Fatalf("expected download to succeed, got %v", err)
+	}
+
+	downloaded, err := os.ReadFile(target)
+	if err != nil || string(downloaded) != string(content) {
+		t.Errorf("expected target to contain the downloaded content, got %q (%v)", downloaded, err)
+	}
+}
+
+func TestHTTPItem_Download_invalidHash(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tampered content"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "mod.jar")
+
+	item := NewHTTPItem(server.URL, target)
+	item.Sha1 = fmt.Sprintf("%x", sha1.Sum([]byte("original content")))
+	err := item.Download(context.Background())
+
+	var invalidSha *ErrInvalidSha
+	if !errors.As(err, &invalidSha) || invalidSha.Algorithm != "sha1" {
+		t.Fatalf("expected sha1 ErrInvalidSha, got %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected no files to be left behind, got %d", len(entries))
+	}
+}
+
+func TestHTTPItem_Download_resume(t *testing.T) {
+	content := []byte("0123456789abcdefghij")
+	var gotRange string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Accept-Ranges", "bytes")
+		http.ServeContent(w, r, "mod.jar", time.Time{}, bytes.NewReader(content))
+		gotRange = r.Header.Get("Range")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "mod.jar")
+
+	// simulate an interrupted download
+	os.WriteFile(target+".part", content[:8], 0644)
+	os.WriteFile(target+".part.validator", []byte(`"v1"`), 0644)
+
+	item := NewHTTPItem(server.URL, target)
+	item.Sha1 = fmt.Sprintf("%x", sha1.Sum(content))
+	if err := item.Download(context.Background()); err != nil {
+		t.Fatalf("expected download to succeed, got %v", err)
+	}
+
+	if gotRange != "bytes=8-" {
+		t.Errorf("expected a range request starting at byte 8, got %q", gotRange)
+	}
+	downloaded, _ := os.ReadFile(target)
+	if string(downloaded) != string(content) {
+		t.Errorf("expected resumed file to match, got %q", downloaded)
+	}
+	if _, err := os.Stat(target + ".part.validator"); !os.IsNotExist(err) {
+		t.Errorf("expected validator to be removed after the download")
+	}
+}
+
+func TestHTTPItem_Download_mirrors(t *testing.T) {
+	content := []byte("mirrored jar")
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer mirror.Close()
+
+	target := filepath.Join(t.TempDir(), "mod.jar")
+	item := NewHTTPItem(down.URL+"/mod.jar", target)
+	item.Mirrors = []string{mirror.URL + "/mod.jar"}
+	item.Sha1 = fmt.Sprintf("%x", sha1.Sum(content))
+	if err := item.Download(context.Background()); err != nil {
+		t.Fatalf("expected download to fail over to the mirror, got %v", err)
+	}
+
+	downloaded, _ := os.ReadFile(target)
+	if string(downloaded) != string(content) {
+		t.Errorf("expected target to contain the mirrored content, got %q", downloaded)
+	}
+}
+
+func TestHostMirror_Rewrite(t *testing.T) {
+	mirror, err := ParseHostMirror("cdn.modrinth.com=https://mirror.example.com/modrinth/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := mirror.Rewrite("https://cdn.modrinth.com/data/AANobbMI/sodium.jar?x=1")
+	if want := "https://mirror.example.com/modrinth/data/AANobbMI/sodium.jar?x=1"; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if got := mirror.Rewrite("https://github.com/some/file.jar"); got != "" {
+		t.Errorf("expected other hosts not to be rewritten, got %s", got)
+	}
+
+	if _, err := ParseHostMirror("cdn.modrinth.com"); err == nil {
+		t.Error("expected mirror without URL to be invalid")
+	}
+}
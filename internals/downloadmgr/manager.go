@@ -23,6 +23,11 @@ func (e *ErrFailedAttempt) Error() string {
 type DownloadManager struct {
 	queue      []*Item
 	OnProgress func(p int)
+	// OnBytes is called periodically while downloading with the combined byte progress of all items.
+	// total only includes items that know their size
+	OnBytes func(transferred int64, total int64)
+	// Scheduler limits the parallel downloads. Defaults to the DefaultScheduler that is shared by all managers
+	Scheduler *Scheduler
 }
 
 type Item struct {
@@ -42,38 +47,75 @@ func (d *DownloadManager) Add(i Downloader) {
 
 // Start starts the download queue
 func (d *DownloadManager) Start(ctx context.Context) error {
-	sem := make(chan int, 16)
 	cErr := make(chan error)
 
 	if d.queue == nil {
 		return nil
 	}
 
-	go func() {
-		for _, item := range d.queue {
-			sem <- 1
-			go func(item *Item, cErr chan error) {
-				for {
-					time.Sleep(time.Duration(item.attempts*item.attempts) * time.Second)
-					err := item.downloader.Download(ctx)
-					if err == nil {
-						cErr <- nil
-						break
-					}
-					item.lastErr = err
-
-					item.attempts += 1
-					if item.attempts >= item.maxAttempts {
-						cErr <- err
-						break
-					} else {
-						cErr <- &ErrFailedAttempt{err}
-					}
-				}
-				<-sem
-			}(item, cErr)
+	// the other downloads are cancelled if we return early (on the first error).
+	// done stops them from waiting for us to receive their result
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	defer close(done)
+	send := func(err error) bool {
+		select {
+		case cErr <- err:
+			return true
+		case <-done:
+			return false
 		}
-	}()
+	}
+
+	scheduler := d.Scheduler
+	if scheduler == nil {
+		scheduler = DefaultScheduler
+	}
+
+	for _, item := range d.queue {
+		go func(item *Item) {
+			for {
+				select {
+				case <-time.After(time.Duration(item.attempts*item.attempts) * time.Second):
+				case <-ctx.Done():
+				}
+				// the slot is only taken while downloading, not while waiting for the next attempt
+				err := scheduler.Run(ctx, item.downloader)
+				if err == nil {
+					send(nil)
+					break
+				}
+				item.lastErr = err
+
+				item.attempts += 1
+				// there is no point in retrying a cancelled download
+				if item.attempts >= item.maxAttempts || ctx.Err() != nil {
+					send(err)
+					break
+				} else if !send(&ErrFailedAttempt{err}) {
+					break
+				}
+			}
+		}(item)
+	}
+
+	if d.OnBytes != nil {
+		stopReporting := make(chan struct{})
+		defer close(stopReporting)
+		go func() {
+			ticker := time.NewTicker(250 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					d.OnBytes(d.Progress())
+				case <-stopReporting:
+					return
+				}
+			}
+		}()
+	}
 
 	// var maybeErr error
 	var attemptType *ErrFailedAttempt
@@ -94,11 +136,31 @@ func (d *DownloadManager) Start(ctx context.Context) error {
 	return nil
 }
 
+// Progress returns the combined byte progress of all items that report it
+func (d *DownloadManager) Progress() (transferred int64, total int64) {
+	for _, item := range d.queue {
+		reporter, ok := item.downloader.(ProgressReporter)
+		if !ok {
+			continue
+		}
+		t, s := reporter.Progress()
+		transferred += t
+		total += s
+	}
+	return transferred, total
+}
+
 // Downloader allows downloadmgr to download the file
 type Downloader interface {
 	Download(ctx context.Context) error
 }
 
+// ProgressReporter is a Downloader that knows how many bytes it downloaded
+type ProgressReporter interface {
+	// Progress returns the downloaded and the total number of bytes (0 if unknown)
+	Progress() (transferred int64, total int64)
+}
+
 // New creates a new downloadmgr
 func New() *DownloadManager {
 	return &DownloadManager{}
@@ -0,0 +1,60 @@
+package downloadmgr
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// funcDownloader calls the function to download
+type funcDownloader func(ctx context.Context) error
+
+func (f funcDownloader) Download(ctx context.Context) error { return f(ctx) }
+
+func TestDownloadManager_Start_cancelsOnError(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	mgr := New()
+	mgr.Scheduler = NewScheduler(4)
+	started := make(chan struct{}, 3)
+	mgr.Add(funcDownloader(func(ctx context.Context) error {
+		// fail once the other downloads are running
+		for i := 0; i < 3; i++ {
+			<-started
+		}
+		return errors.New("not found")
+	}))
+	mgr.queue[0].maxAttempts = 1
+
+	cancelled := make(chan struct{}, 3)
+	for i := 0; i < 3; i++ {
+		mgr.Add(funcDownloader(func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			cancelled <- struct{}{}
+			return ctx.Err()
+		}))
+	}
+
+	if err := mgr.Start(context.Background()); err == nil || err.Error() != "not found" {
+		t.Fatalf("expected the error of the failed download, got %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-cancelled:
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected the other downloads to be cancelled")
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected all download goroutines to exit, %d are left", runtime.NumGoroutine()-before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,68 @@
+package downloadmgr
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// HostMirror serves the files of another host (eg. an internal mirror of cdn.modrinth.com)
+type HostMirror struct {
+	// Host that is mirrored (eg. "cdn.modrinth.com")
+	Host string
+	// Mirror is the base URL that replaces the scheme & host (eg. "https://mirror.example.com/modrinth")
+	Mirror *url.URL
+}
+
+// HostMirrors are tried before the original host for every download
+var HostMirrors []*HostMirror
+
+// ParseHostMirror parses a mirror in the "host=https://mirror.example.com/path" format
+func ParseHostMirror(raw string) (*HostMirror, error) {
+	host, mirror, ok := strings.Cut(raw, "=")
+	if !ok || host == "" || mirror == "" {
+		return nil, fmt.Errorf("invalid mirror %q: expected the host=https://mirror.example.com format", raw)
+	}
+
+	parsed, err := url.Parse(mirror)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid mirror %q: %s is not an absolute URL", raw, mirror)
+	}
+
+	return &HostMirror{Host: strings.TrimSpace(host), Mirror: parsed}, nil
+}
+
+// Rewrite returns the URL on the mirror or "" if the URL is not from the mirrored host
+func (m *HostMirror) Rewrite(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || !strings.EqualFold(parsed.Host, m.Host) {
+		return ""
+	}
+
+	rewritten := *m.Mirror
+	rewritten.Path = strings.TrimSuffix(m.Mirror.Path, "/") + parsed.Path
+	rewritten.RawPath = ""
+	rewritten.RawQuery = parsed.RawQuery
+	return rewritten.String()
+}
+
+// withMirrors returns the URLs with their mirrors in front of them. Duplicates are removed
+func withMirrors(urls []string) []string {
+	seen := make(map[string]bool, len(urls))
+	sources := make([]string, 0, len(urls))
+	add := func(u string) {
+		if u == "" || seen[u] {
+			return
+		}
+		seen[u] = true
+		sources = append(sources, u)
+	}
+
+	for _, u := range urls {
+		for _, mirror := range HostMirrors {
+			add(mirror.Rewrite(u))
+		}
+		add(u)
+	}
+	return sources
+}
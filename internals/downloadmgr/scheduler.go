@@ -0,0 +1,33 @@
+package downloadmgr
+
+import "context"
+
+// DefaultScheduler is shared by all download managers that do not set their own scheduler.
+// This keeps the number of parallel downloads bounded, even if java, Minecraft and mods are
+// downloaded at the same time
+var DefaultScheduler = NewScheduler(16)
+
+// Scheduler limits the number of downloads that run at the same time
+type Scheduler struct {
+	slots chan struct{}
+}
+
+// NewScheduler returns a scheduler that allows up to `parallel` downloads at the same time
+func NewScheduler(parallel int) *Scheduler {
+	if parallel < 1 {
+		parallel = 1
+	}
+	return &Scheduler{slots: make(chan struct{}, parallel)}
+}
+
+// Run waits for a free slot and then downloads the item
+func (s *Scheduler) Run(ctx context.Context, d Downloader) error {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-s.slots }()
+
+	return d.Download(ctx)
+}
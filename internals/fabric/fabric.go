@@ -24,5 +24,17 @@ type Manifest struct {
 	LanguageAdapters map[string]string   `json:"languageAdapters,omitempty"`
 	Mixins           []interface{}       `json:"mixins,omitempty"`
 	Depends          map[string]StrArray `json:"depends,omitempty"`
+	Breaks           map[string]StrArray `json:"breaks,omitempty"`
+	Provides         []string            `json:"provides,omitempty"`
 	Custom           interface{}         `json:"custom,omitempty"`
 }
+
+// Side returns "client" or "server" if the mod only runs on one side and "" if it runs on both
+func (m *Manifest) Side() string {
+	switch m.Environment {
+	case "client", "server":
+		return m.Environment
+	default:
+		return ""
+	}
+}
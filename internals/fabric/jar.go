@@ -0,0 +1,113 @@
+package fabric
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrNoFabricManifest is returned if a jar does not contain a fabric.mod.json
+var ErrNoFabricManifest = errors.New("jar does not contain a fabric.mod.json")
+
+// Jar is a fabric mod jar and the jars nested inside it (jar-in-jar)
+type Jar struct {
+	// Manifest is the parsed fabric.mod.json
+	Manifest *Manifest
+	// Nested contains the jars listed in the "jars" field of the manifest
+	Nested []*Jar
+}
+
+// ReadJar reads the fabric.mod.json of a mod jar and all nested jars
+func ReadJar(r io.ReaderAt, size int64) (*Jar, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return readJar(archive)
+}
+
+// ReadManifestFile reads only the fabric.mod.json of the jar at the given path (nested jars are ignored)
+func ReadManifestFile(p string) (*Manifest, error) {
+	archive, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	modJSON, err := readZipFile(&archive.Reader, "fabric.mod.json")
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(modJSON, manifest); err != nil {
+		return nil, fmt.Errorf("invalid fabric.mod.json: %w", err)
+	}
+	return manifest, nil
+}
+
+func readJar(archive *zip.Reader) (*Jar, error) {
+	modJSON, err := readZipFile(archive, "fabric.mod.json")
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(modJSON, manifest); err != nil {
+		return nil, fmt.Errorf("invalid fabric.mod.json: %w", err)
+	}
+
+	jar := &Jar{Manifest: manifest}
+	for _, nested := range manifest.Jars {
+		content, err := readZipFile(archive, nested.File)
+		if err != nil {
+			return nil, fmt.Errorf("could not read nested jar %s: %w", nested.File, err)
+		}
+
+		nestedArchive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return nil, fmt.Errorf("could not read nested jar %s: %w", nested.File, err)
+		}
+
+		nestedJar, err := readJar(nestedArchive)
+		// nested jars are not required to be fabric mods (eg. plain java libraries)
+		if err == ErrNoFabricManifest {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		jar.Nested = append(jar.Nested, nestedJar)
+	}
+
+	return jar, nil
+}
+
+// ProvidedIDs returns the ids of this mod, the ids it provides and those of all nested mods
+func (j *Jar) ProvidedIDs() []string {
+	ids := append([]string{j.Manifest.ID}, j.Manifest.Provides...)
+	for _, nested := range j.Nested {
+		ids = append(ids, nested.ProvidedIDs()...)
+	}
+	return ids
+}
+
+func readZipFile(archive *zip.Reader, name string) ([]byte, error) {
+	// zip paths can not start with "/" or "./"
+	file, err := archive.Open(path.Clean(strings.TrimPrefix(name, "/")))
+	if err != nil {
+		if name == "fabric.mod.json" {
+			return nil, ErrNoFabricManifest
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
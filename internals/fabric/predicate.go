@@ -0,0 +1,42 @@
+package fabric
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// VersionMatches returns true if the version satisfies one of the version predicates
+// of a `depends` or `breaks` entry (eg. ">=0.14.0", "1.20.x" or "*").
+// Predicates that can not be parsed are treated as satisfied, the loader has the final say
+func VersionMatches(predicates []string, version string) bool {
+	if len(predicates) == 0 {
+		return true
+	}
+	for _, predicate := range predicates {
+		if predicateMatches(strings.TrimSpace(predicate), version) {
+			return true
+		}
+	}
+	return false
+}
+
+func predicateMatches(predicate string, version string) bool {
+	if predicate == "" || predicate == "*" {
+		return true
+	}
+
+	parsed, err := semver.NewVersion(version)
+	if err != nil {
+		// versions that are not semver can only be compared exactly
+		return strings.TrimPrefix(predicate, "=") == version
+	}
+
+	constraint, err := semver.NewConstraint(predicate)
+	if err != nil {
+		return true
+	}
+	// fabric does not treat pre-releases differently
+	constraint.IncludePrerelease = true
+	return constraint.Check(parsed)
+}
@@ -0,0 +1,478 @@
+package forge
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/minepkg/minepkg/internals/minecraft"
+)
+
+var (
+	// ErrNoInstallProfile is returned if a jar does not contain an install_profile.json
+	ErrNoInstallProfile = errors.New("installer does not contain an install_profile.json")
+	// ErrNoServerArgs is returned if an installer does not contain the arguments to launch a server
+	ErrNoServerArgs = errors.New("installer does not contain server launch arguments")
+)
+
+// Installer is a (neo)forge installer jar. Both loaders use the same installer format
+type Installer struct {
+	// Profile is the parsed install_profile.json
+	Profile *InstallProfile
+	// LaunchManifest is the parsed version.json. It inherits from the vanilla launch manifest
+	LaunchManifest *minecraft.LaunchManifest
+	// RawLaunchManifest is the unparsed version.json
+	RawLaunchManifest []byte
+
+	path    string
+	archive *zip.ReadCloser
+}
+
+// InstallProfile is the install_profile.json of an installer.
+// It lists the processors that need to run before the game can be launched
+type InstallProfile struct {
+	Spec      int    `json:"spec"`
+	Version   string `json:"version"`
+	Minecraft string `json:"minecraft"`
+	// JSON is the path of the launch manifest in the installer (usually "/version.json")
+	JSON string `json:"json"`
+	// Data contains variables that can be used in processor arguments (eg. "{MAPPINGS}")
+	Data map[string]DataEntry `json:"data"`
+	// Processors are java programs that patch the minecraft jar
+	Processors []Processor `json:"processors"`
+	// Libraries are needed to run the processors (they are not needed to launch the game)
+	Libraries []minecraft.Library `json:"libraries"`
+
+	// Install and VersionInfo are only used by legacy installers (Minecraft 1.12 and older).
+	// VersionInfo is the launch manifest, there are no processors
+	Install     *LegacyInstall  `json:"install,omitempty"`
+	VersionInfo json.RawMessage `json:"versionInfo,omitempty"`
+}
+
+// LegacyInstall describes the forge jar bundled with a legacy installer
+type LegacyInstall struct {
+	// Path is the maven coordinate the jar is installed as
+	Path string `json:"path"`
+	// FilePath is the name of the jar in the installer
+	FilePath  string `json:"filePath"`
+	Minecraft string `json:"minecraft"`
+}
+
+// DataEntry is a variable with different values on the client and server
+type DataEntry struct {
+	Client string `json:"client"`
+	Server string `json:"server"`
+}
+
+// Processor is a java program that runs while installing
+type Processor struct {
+	// Sides the processor runs on. It runs on both sides if this is empty
+	Sides []string `json:"sides"`
+	// Jar is the maven coordinate of the processor jar
+	Jar string `json:"jar"`
+	// Classpath contains maven coordinates of the libraries the processor needs
+	Classpath []string `json:"classpath"`
+	Args      []string `json:"args"`
+	// Outputs maps created files to their sha1 hash. Both can be variables
+	Outputs map[string]string `json:"outputs"`
+}
+
+// RunsOn returns true if the processor needs to run on the given side ("client" or "server")
+func (p *Processor) RunsOn(side string) bool {
+	if len(p.Sides) == 0 {
+		return true
+	}
+	for _, s := range p.Sides {
+		if s == side {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenInstaller opens the installer jar at the given path and reads its profiles.
+// The installer has to be closed after use
+func OpenInstaller(p string) (*Installer, error) {
+	archive, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, err
+	}
+	installer := &Installer{path: p, archive: archive}
+
+	profile := &InstallProfile{}
+	if err := installer.readJSON("install_profile.json", profile); err != nil {
+		archive.Close()
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoInstallProfile
+		}
+		return nil, fmt.Errorf("invalid install_profile.json: %w", err)
+	}
+	installer.Profile = profile
+
+	manifestPath := strings.TrimPrefix(profile.JSON, "/")
+	if manifestPath == "" {
+		manifestPath = "version.json"
+	}
+	rawManifest := []byte(profile.VersionInfo)
+	if profile.Install != nil {
+		profile.Minecraft = profile.Install.Minecraft
+	} else if rawManifest, err = installer.readFile(manifestPath); err != nil {
+		archive.Close()
+		return nil, fmt.Errorf("missing %s: %w", manifestPath, err)
+	}
+	launchManifest := &minecraft.LaunchManifest{}
+	if err := json.Unmarshal(rawManifest, launchManifest); err != nil {
+		archive.Close()
+		return nil, fmt.Errorf("invalid %s: %w", manifestPath, err)
+	}
+	installer.LaunchManifest = launchManifest
+	installer.RawLaunchManifest = rawManifest
+
+	return installer, nil
+}
+
+// Close closes the installer jar
+func (i *Installer) Close() error {
+	return i.archive.Close()
+}
+
+func (i *Installer) readJSON(name string, v interface{}) error {
+	buf, err := i.readFile(name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, v)
+}
+
+func (i *Installer) readFile(name string) ([]byte, error) {
+	f, err := i.archive.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// ExtractLibraries extracts the libraries bundled in the "maven/" folder of the installer into `librariesDir`.
+// Legacy installers bundle the forge jar in the root folder instead. Existing files are not overwritten
+func (i *Installer) ExtractLibraries(librariesDir string) error {
+	for _, f := range i.archive.File {
+		var target string
+		switch {
+		case f.FileInfo().IsDir():
+			continue
+		case strings.HasPrefix(f.Name, "maven/"):
+			target = filepath.Join(librariesDir, filepath.FromSlash(strings.TrimPrefix(f.Name, "maven/")))
+		case i.Profile.Install != nil && f.Name == i.Profile.Install.FilePath:
+			target = filepath.Join(librariesDir, ArtifactPath(i.Profile.Install.Path))
+		default:
+			continue
+		}
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+		if err := extractFile(f, librariesDir, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServerArgs returns the arguments to launch a server from the "data/unix_args.txt" (or "data/win_args.txt")
+// of the installer. Relative library paths are resolved against `librariesDir`.
+// Returns ErrNoServerArgs for installers that do not contain these files (Forge for Minecraft 1.16 and older)
+func (i *Installer) ServerArgs(librariesDir string) (*ServerArgs, error) {
+	name := "data/unix_args.txt"
+	if runtime.GOOS == "windows" {
+		name = "data/win_args.txt"
+	}
+	content, err := i.readFile(name)
+	if err != nil {
+		return nil, ErrNoServerArgs
+	}
+	return ParseServerArgs(string(content), librariesDir)
+}
+
+// ServerArgs are the arguments to launch a server
+type ServerArgs struct {
+	JVM       []string
+	MainClass string
+	Game      []string
+}
+
+// librariesPath matches the relative "libraries" folder in server args
+var librariesPath = regexp.MustCompile(`(^|[=:;,])libraries(/|$)`)
+
+// ParseServerArgs parses an args file that contains one or more arguments per line.
+// The main class is the only line that does not start with a dash
+func ParseServerArgs(content string, librariesDir string) (*ServerArgs, error) {
+	args := &ServerArgs{}
+	replacement := "${1}" + strings.ReplaceAll(filepath.ToSlash(librariesDir), "$", "$$") + "${2}"
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if args.MainClass == "" && !strings.HasPrefix(line, "-") && !strings.Contains(line, " ") {
+			args.MainClass = line
+			continue
+		}
+
+		for _, arg := range strings.Fields(line) {
+			arg = librariesPath.ReplaceAllString(arg, replacement)
+			if args.MainClass == "" {
+				args.JVM = append(args.JVM, arg)
+			} else {
+				args.Game = append(args.Game, arg)
+			}
+		}
+	}
+
+	if args.MainClass == "" {
+		return nil, errors.New("server args do not contain a main class")
+	}
+	return args, nil
+}
+
+// InstallOptions are used to run the processors of an installer
+type InstallOptions struct {
+	// Java is the java executable that runs the processors
+	Java string
+	// Side is either "client" or "server"
+	Side string
+	// LibrariesDir contains all libraries of the install profile
+	LibrariesDir string
+	// MinecraftJar is the path to the vanilla Minecraft jar
+	MinecraftJar string
+	// TempDir is used for files extracted from the installer. It is not removed
+	TempDir string
+	// Output receives the output of the processors. Can be nil
+	Output io.Writer
+}
+
+// RunProcessors runs all processors for the given side.
+// Processors are skipped if all their outputs already exist with the expected hash
+func (i *Installer) RunProcessors(ctx context.Context, opts *InstallOptions) error {
+	data, err := i.data(opts)
+	if err != nil {
+		return err
+	}
+
+	for _, processor := range i.Profile.Processors {
+		if !processor.RunsOn(opts.Side) {
+			continue
+		}
+
+		outputs := make(map[string]string, len(processor.Outputs))
+		for file, hash := range processor.Outputs {
+			outputs[resolveArgument(file, data, opts.LibrariesDir)] = resolveArgument(hash, data, opts.LibrariesDir)
+		}
+		if len(outputs) != 0 && verifyOutputs(outputs) == nil {
+			continue
+		}
+
+		if err := i.runProcessor(ctx, &processor, data, opts); err != nil {
+			return fmt.Errorf("processor %s failed: %w", processor.Jar, err)
+		}
+		if err := verifyOutputs(outputs); err != nil {
+			return fmt.Errorf("processor %s failed: %w", processor.Jar, err)
+		}
+	}
+
+	return nil
+}
+
+func (i *Installer) runProcessor(ctx context.Context, processor *Processor, data map[string]string, opts *InstallOptions) error {
+	jar := filepath.Join(opts.LibrariesDir, ArtifactPath(processor.Jar))
+	mainClass, err := jarMainClass(jar)
+	if err != nil {
+		return err
+	}
+
+	classpath := []string{jar}
+	for _, library := range processor.Classpath {
+		classpath = append(classpath, filepath.Join(opts.LibrariesDir, ArtifactPath(library)))
+	}
+
+	args := []string{"-cp", strings.Join(classpath, string(os.PathListSeparator)), mainClass}
+	for _, arg := range processor.Args {
+		args = append(args, resolveArgument(arg, data, opts.LibrariesDir))
+	}
+
+	cmd := exec.CommandContext(ctx, opts.Java, args...)
+	cmd.Stdout = opts.Output
+	cmd.Stderr = opts.Output
+	return cmd.Run()
+}
+
+// data returns all variables that can be used in processor arguments with their resolved value
+func (i *Installer) data(opts *InstallOptions) (map[string]string, error) {
+	data := map[string]string{
+		"SIDE":              opts.Side,
+		"MINECRAFT_JAR":     opts.MinecraftJar,
+		"MINECRAFT_VERSION": i.Profile.Minecraft,
+		"ROOT":              filepath.Dir(opts.LibrariesDir),
+		"INSTALLER":         i.path,
+		"LIBRARY_DIR":       opts.LibrariesDir,
+	}
+
+	for key, entry := range i.Profile.Data {
+		value := entry.Client
+		if opts.Side == "server" {
+			value = entry.Server
+		}
+
+		switch {
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			data[key] = filepath.Join(opts.LibrariesDir, ArtifactPath(value[1:len(value)-1]))
+		case strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'"):
+			data[key] = value[1 : len(value)-1]
+		case strings.HasPrefix(value, "/"):
+			// a file inside the installer (eg. "/data/client.lzma")
+			target := filepath.Join(opts.TempDir, filepath.FromSlash(value))
+			f, err := i.archive.Open(strings.TrimPrefix(value, "/"))
+			if err != nil {
+				return nil, fmt.Errorf("installer data %s: %w", key, err)
+			}
+			err = writeFile(f, target)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+			data[key] = target
+		default:
+			data[key] = value
+		}
+	}
+
+	return data, nil
+}
+
+// resolveArgument replaces "{VARIABLE}" with its value and "[maven:coordinate]" with the library path
+func resolveArgument(arg string, data map[string]string, librariesDir string) string {
+	switch {
+	case strings.HasPrefix(arg, "{") && strings.HasSuffix(arg, "}"):
+		if value, ok := data[arg[1:len(arg)-1]]; ok {
+			return value
+		}
+		return arg
+	case strings.HasPrefix(arg, "[") && strings.HasSuffix(arg, "]"):
+		return filepath.Join(librariesDir, ArtifactPath(arg[1:len(arg)-1]))
+	case strings.HasPrefix(arg, "'") && strings.HasSuffix(arg, "'"):
+		return arg[1 : len(arg)-1]
+	default:
+		return arg
+	}
+}
+
+// ArtifactPath returns the relative path of a maven coordinate in the "group:artifact:version[:classifier][@extension]" format
+func ArtifactPath(coordinate string) string {
+	extension := "jar"
+	if at := strings.LastIndex(coordinate, "@"); at != -1 {
+		extension = coordinate[at+1:]
+		coordinate = coordinate[:at]
+	}
+
+	parts := strings.Split(coordinate, ":")
+	if len(parts) < 3 {
+		return filepath.FromSlash(coordinate)
+	}
+	group, artifact, version := parts[0], parts[1], parts[2]
+
+	name := artifact + "-" + version
+	if len(parts) > 3 && parts[3] != "" {
+		name += "-" + parts[3]
+	}
+
+	return filepath.Join(
+		filepath.FromSlash(strings.ReplaceAll(group, ".", "/")),
+		artifact,
+		version,
+		name+"."+extension,
+	)
+}
+
+// verifyOutputs returns an error if an output file is missing or has the wrong sha1 hash
+func verifyOutputs(outputs map[string]string) error {
+	for file, hash := range outputs {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		hasher := sha1.New()
+		_, err = io.Copy(hasher, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		if got := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(got, hash) {
+			return fmt.Errorf("%s has the sha1 hash %s, expected %s", file, got, hash)
+		}
+	}
+	return nil
+}
+
+// jarMainClass reads the Main-Class from the META-INF/MANIFEST.MF of a jar
+func jarMainClass(jar string) (string, error) {
+	archive, err := zip.OpenReader(jar)
+	if err != nil {
+		return "", err
+	}
+	defer archive.Close()
+
+	f, err := archive.Open("META-INF/MANIFEST.MF")
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path.Base(jar), err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if mainClass, ok := strings.CutPrefix(scanner.Text(), "Main-Class:"); ok {
+			return strings.TrimSpace(mainClass), nil
+		}
+	}
+	return "", fmt.Errorf("%s does not have a Main-Class", path.Base(jar))
+}
+
+func extractFile(f *zip.File, root string, target string) error {
+	// to avoid zip slip (writing outside of the destination)
+	if !strings.HasPrefix(target, filepath.Clean(root)+string(os.PathSeparator)) {
+		return fmt.Errorf("%s: illegal file path", f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return writeFile(rc, target)
+}
+
+func writeFile(r io.Reader, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
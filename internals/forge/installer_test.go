@@ -0,0 +1,187 @@
+package forge
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArtifactPath(t *testing.T) {
+	tests := map[string]string{
+		"net.neoforged:neoforge:21.1.77":                  "net/neoforged/neoforge/21.1.77/neoforge-21.1.77.jar",
+		"net.neoforged:neoforge:21.1.77:client":           "net/neoforged/neoforge/21.1.77/neoforge-21.1.77-client.jar",
+		"net.neoforged:neoform:1.21.1-20240808@zip":       "net/neoforged/neoform/1.21.1-20240808/neoform-1.21.1-20240808.zip",
+		"de.oceanlabs.mcp:mcp_config:1.20.1:mappings@txt": "de/oceanlabs/mcp/mcp_config/1.20.1/mcp_config-1.20.1-mappings.txt",
+	}
+	for coordinate, want := range tests {
+		if got := ArtifactPath(coordinate); got != filepath.FromSlash(want) {
+			t.Errorf("ArtifactPath(%q) = %q, want %q", coordinate, got, want)
+		}
+	}
+}
+
+func writeTestInstaller(t *testing.T, files map[string]string) string {
+	p := filepath.Join(t.TempDir(), "installer.jar")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return p
+}
+
+func TestInstaller_RunProcessors(t *testing.T) {
+	output := "patched minecraft"
+	sum := sha1.Sum([]byte(output))
+
+	installerPath := writeTestInstaller(t, map[string]string{
+		"install_profile.json": `{
+			"spec": 1,
+			"minecraft": "1.21.1",
+			"json": "/version.json",
+			"data": {
+				"PATCHED": {"client": "[net.neoforged:neoforge:21.1.77:client]", "server": "[net.neoforged:neoforge:21.1.77:server]"},
+				"PATCHED_SHA": {"client": "'` + hex.EncodeToString(sum[:]) + `'", "server": "'0000'"},
+				"BINPATCH": {"client": "/data/client.lzma", "server": "/data/server.lzma"}
+			},
+			"processors": [
+				{"sides": ["server"], "jar": "net.neoforged.installertools:installertools:2.1.2", "args": ["{BINPATCH}"]},
+				{"jar": "net.neoforged.installertools:binarypatcher:2.1.2", "args": ["--patch", "{BINPATCH}"], "outputs": {"{PATCHED}": "{PATCHED_SHA}"}}
+			]
+		}`,
+		"version.json":     `{"id": "neoforge-21.1.77", "inheritsFrom": "1.21.1", "mainClass": "cpw.mods.bootstraplauncher.BootstrapLauncher"}`,
+		"data/client.lzma": "binpatches",
+		"maven/net/neoforged/neoforge/21.1.77/neoforge-21.1.77-universal.jar": "universal",
+	})
+
+	installer, err := OpenInstaller(installerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer installer.Close()
+
+	if installer.LaunchManifest.InheritsFrom != "1.21.1" || !strings.Contains(string(installer.RawLaunchManifest), "neoforge-21.1.77") {
+		t.Fatalf("unexpected launch manifest %+v", installer.LaunchManifest)
+	}
+
+	librariesDir := filepath.Join(t.TempDir(), "libraries")
+	if err := installer.ExtractLibraries(librariesDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(librariesDir, ArtifactPath("net.neoforged:neoforge:21.1.77:universal"))); err != nil {
+		t.Errorf("bundled library was not extracted: %s", err)
+	}
+
+	// the output already exists, so no processor needs java
+	patched := filepath.Join(librariesDir, ArtifactPath("net.neoforged:neoforge:21.1.77:client"))
+	os.MkdirAll(filepath.Dir(patched), os.ModePerm)
+	os.WriteFile(patched, []byte(output), 0644)
+
+	opts := &InstallOptions{
+		Java:         filepath.Join(t.TempDir(), "no-java"),
+		Side:         "client",
+		LibrariesDir: librariesDir,
+		TempDir:      t.TempDir(),
+	}
+	if err := installer.RunProcessors(context.Background(), opts); err != nil {
+		t.Fatalf("expected all processors to be skipped, got %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(opts.TempDir, "data", "client.lzma")); err != nil {
+		t.Errorf("installer data was not extracted: %s", err)
+	}
+
+	// a changed output needs the processor to run again
+	os.WriteFile(patched, []byte("outdated"), 0644)
+	if err := installer.RunProcessors(context.Background(), opts); err == nil {
+		t.Error("expected the processor to run (and fail without java)")
+	}
+}
+
+func TestOpenInstaller_legacy(t *testing.T) {
+	installerPath := writeTestInstaller(t, map[string]string{
+		"install_profile.json": `{
+			"install": {
+				"path": "net.minecraftforge:forge:1.12.2-14.23.5.2859",
+				"filePath": "forge-1.12.2-14.23.5.2859-universal.jar",
+				"minecraft": "1.12.2"
+			},
+			"versionInfo": {"id": "1.12.2-forge1.12.2-14.23.5.2859", "inheritsFrom": "1.12.2", "mainClass": "net.minecraft.launchwrapper.Launch"}
+		}`,
+		"forge-1.12.2-14.23.5.2859-universal.jar": "universal",
+	})
+
+	installer, err := OpenInstaller(installerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer installer.Close()
+
+	if installer.Profile.Minecraft != "1.12.2" || installer.LaunchManifest.MainClass != "net.minecraft.launchwrapper.Launch" {
+		t.Fatalf("unexpected legacy profile %+v", installer.Profile)
+	}
+
+	librariesDir := t.TempDir()
+	if err := installer.ExtractLibraries(librariesDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(librariesDir, ArtifactPath("net.minecraftforge:forge:1.12.2-14.23.5.2859"))); err != nil {
+		t.Errorf("forge jar was not extracted: %s", err)
+	}
+
+	if _, err := installer.ServerArgs(librariesDir); err != ErrNoServerArgs {
+		t.Errorf("expected ErrNoServerArgs, got %v", err)
+	}
+}
+
+func TestParseServerArgs(t *testing.T) {
+	content := `-Djava.net.preferIPv6Addresses=system
+-DlibraryDirectory=libraries
+-p libraries/cpw/mods/bootstraplauncher/1.1.2/bootstraplauncher-1.1.2.jar:libraries/cpw/mods/securejarhandler/2.1.10/securejarhandler-2.1.10.jar
+--add-opens java.base/java.util.jar=cpw.mods.securejarhandler
+cpw.mods.bootstraplauncher.BootstrapLauncher
+--launchTarget forgeserver
+--fml.forgeVersion 47.2.0
+`
+	args, err := ParseServerArgs(content, "/opt/minepkg/libraries")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if args.MainClass != "cpw.mods.bootstraplauncher.BootstrapLauncher" {
+		t.Errorf("unexpected main class %q", args.MainClass)
+	}
+	wantJVM := []string{
+		"-Djava.net.preferIPv6Addresses=system",
+		"-DlibraryDirectory=/opt/minepkg/libraries",
+		"-p",
+		"/opt/minepkg/libraries/cpw/mods/bootstraplauncher/1.1.2/bootstraplauncher-1.1.2.jar:/opt/minepkg/libraries/cpw/mods/securejarhandler/2.1.10/securejarhandler-2.1.10.jar",
+		"--add-opens",
+		"java.base/java.util.jar=cpw.mods.securejarhandler",
+	}
+	if strings.Join(args.JVM, " ") != strings.Join(wantJVM, " ") {
+		t.Errorf("unexpected jvm args\n got: %v\nwant: %v", args.JVM, wantJVM)
+	}
+	if strings.Join(args.Game, " ") != "--launchTarget forgeserver --fml.forgeVersion 47.2.0" {
+		t.Errorf("unexpected game args %v", args.Game)
+	}
+
+	if _, err := ParseServerArgs("-Xmx1G\n", "libraries"); err == nil {
+		t.Error("expected an error without a main class")
+	}
+}
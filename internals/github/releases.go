@@ -3,10 +3,16 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 )
 
+// ErrRepositoryNotFound is returned if the repository does not exist (or is private)
+var ErrRepositoryNotFound = errors.New("repository not found")
+
 type Release struct {
 	URL       string `json:"url"`
 	AssetsURL string `json:"assets_url"`
@@ -96,3 +102,58 @@ func GetLatestRelease(ctx context.Context, repo string) (*Release, error) {
 	err = json.NewDecoder(resp.Body).Decode(&release)
 	return &release, err
 }
+
+// DefaultAPIURL is the url of the public GitHub api
+const DefaultAPIURL = "https://api.github.com/"
+
+// Client can be used to query releases of a GitHub repository
+type Client struct {
+	HTTP *http.Client
+	// BaseURL is the api url, defaults to DefaultAPIURL
+	BaseURL string
+	// Token is used for authentication if set (allows higher rate limits)
+	Token string
+}
+
+// NewClient returns a Client for the public GitHub api
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{HTTP: httpClient, BaseURL: DefaultAPIURL}
+}
+
+// ListReleases returns the (up to 100) newest releases of the given repository (eg. "minepkg/minepkg")
+func (c *Client) ListReleases(ctx context.Context, repo string) ([]Release, error) {
+	reqURL, err := url.JoinPath(c.BaseURL, "repos", repo, "releases")
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL+"?per_page=100", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrRepositoryNotFound
+	default:
+		return nil, fmt.Errorf("unexpected status code %d from github", resp.StatusCode)
+	}
+
+	var releases []Release
+	err = json.NewDecoder(resp.Body).Decode(&releases)
+	return releases, err
+}
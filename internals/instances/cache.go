@@ -0,0 +1,55 @@
+package instances
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/minepkg/minepkg/internals/pkgcache"
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+// ReferencedPackages returns the package cache keys (see pkgcache.Key) of all dependencies
+// locked by the instances in InstancesDir and the given additional instance directories
+func (i *Instance) ReferencedPackages(extraDirs ...string) (map[string]bool, error) {
+	dirs := append([]string{}, extraDirs...)
+
+	entries, err := os.ReadDir(i.InstancesDir())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(i.InstancesDir(), entry.Name()))
+		}
+	}
+
+	referenced := make(map[string]bool)
+	for _, dir := range dirs {
+		lockfile, err := readLockfileIn(dir)
+		if err != nil {
+			return nil, err
+		}
+		if lockfile == nil {
+			continue
+		}
+		for _, dep := range lockfile.Dependencies {
+			referenced[pkgcache.Key(dep)] = true
+		}
+	}
+
+	return referenced, nil
+}
+
+// readLockfileIn reads the lockfile of the instance in dir. Returns nil if there is none
+func readLockfileIn(dir string) (*manifest.Lockfile, error) {
+	for _, name := range []string{".minepkg-lock.toml", "minepkg-lock.toml"} {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		return manifest.NewLockfileFromFile(p)
+	}
+	return nil, nil
+}
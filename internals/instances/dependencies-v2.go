@@ -75,6 +75,7 @@ func (d *Dependency) ProviderRequest() *provider.Request {
 		Dependency:   d.ID,
 		Requirements: d.instance.Lockfile.PlatformLock(),
 		// Root:         d.instance.Lockfile.,
+		Dir: d.instance.Directory,
 	}
 }
 
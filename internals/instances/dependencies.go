@@ -2,18 +2,30 @@ package instances
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"log"
 	"os"
 	"path/filepath"
 	"runtime"
 
+	"github.com/jwalton/gchalk"
+	"github.com/minepkg/minepkg/internals/commands"
 	"github.com/minepkg/minepkg/internals/downloadmgr"
+	"github.com/minepkg/minepkg/internals/fabric"
 	"github.com/minepkg/minepkg/internals/pack"
+	"github.com/minepkg/minepkg/internals/pkgcache"
 	"github.com/minepkg/minepkg/internals/resolver"
 	"github.com/minepkg/minepkg/pkg/manifest"
 )
 
 func (i *Instance) GetResolver(ctx context.Context) (*resolver.Resolver, error) {
+	if i.Offline {
+		return nil, fmt.Errorf("can not resolve dependencies: %w", ErrOffline)
+	}
 	if i.Lockfile == nil {
 		i.Lockfile = manifest.NewLockfile()
 		if err := i.UpdateLockfileRequirements(ctx); err != nil {
@@ -23,8 +35,9 @@ func (i *Instance) GetResolver(ctx context.Context) (*resolver.Resolver, error)
 		i.Lockfile.ClearDependencies()
 	}
 
-	// add our companion mod if not disabled by user or non fabric
-	if i.Manifest.Requirements.MinepkgCompanion != "none" && i.Manifest.PlatformString() == "fabric" {
+	// add our companion mod if not disabled by user or non fabric (quilt can load it too)
+	platform := i.Manifest.PlatformString()
+	if i.Manifest.Requirements.MinepkgCompanion != "none" && (platform == "fabric" || platform == "quilt") {
 		// just add it to the manifest. this is pretty hacky
 		v := "latest"
 		if i.Manifest.Requirements.MinepkgCompanion != "" {
@@ -38,23 +51,38 @@ func (i *Instance) GetResolver(ctx context.Context) (*resolver.Resolver, error)
 	// only include dev dependencies if this instance was created from a working directory
 	// (eg. typing "minepkg launch" in a directory with a minepkg.toml)
 	res.IncludeDev = i.isFromWd
-	// res.AlsoDownload = true
+	res.Dir = i.Directory
+	// packages are downloaded while the rest is still resolving
+	res.AlsoDownload = true
+	res.Download = i.DownloadDependency
 
 	return res, nil
 }
 
-// UpdateLockfileDependencies resolves all dependencies
+// UpdateLockfileDependencies resolves all dependencies and downloads them while resolving
 func (i *Instance) UpdateLockfileDependencies(ctx context.Context) error {
+	return i.updateLockfileDependencies(ctx, true)
+}
+
+// ResolveLockfileDependencies resolves all dependencies and updates the lockfile.
+// Only jars that are needed for the mod index are downloaded (see IndexDependencies)
+func (i *Instance) ResolveLockfileDependencies(ctx context.Context) error {
+	return i.updateLockfileDependencies(ctx, false)
+}
+
+func (i *Instance) updateLockfileDependencies(ctx context.Context, download bool) error {
 	resolver, err := i.GetResolver(ctx)
 	if err != nil {
 		return err
 	}
+	resolver.AlsoDownload = download
 	if err := resolver.Resolve(ctx); err != nil {
 		return err
 	}
 
-	for _, lock := range resolver.Resolved {
-		i.Lockfile.AddDependency(lock)
+	i.Lockfile.SetDependencies(resolver.Resolved)
+	if err := i.IndexDependencies(ctx); err != nil {
+		return err
 	}
 
 	// This is kind of a hack
@@ -71,12 +99,16 @@ func (i *Instance) FindMissingDependencies() ([]*manifest.DependencyLock, error)
 	deps := i.Lockfile.Dependencies
 
 	for _, dep := range deps {
-		if dep.URL == "" {
+		if !dep.HasSource() {
 			continue // skip dependencies without download url
 		}
-		p := filepath.Join(dep.Name, dep.Version+dep.FileExt())
-		if _, err := os.Stat(filepath.Join(i.PackageCacheDir(), p)); os.IsNotExist(err) {
+		_, err := i.PackageCache().Path(dep)
+		if errors.Is(err, pkgcache.ErrNotCached) {
 			missing = append(missing, dep)
+			continue
+		}
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -102,10 +134,9 @@ func (i *Instance) LinkDependencies() error {
 
 	for _, dep := range i.Lockfile.Dependencies {
 		// skip packages with no binary
-		if dep.URL == "" {
+		if !dep.HasSource() {
 			continue
 		}
-		from := filepath.Join(i.PackageCacheDir(), dep.Name, dep.Version+dep.FileExt())
 		to := filepath.Join(i.ModsDir(), dep.Filename())
 
 		// extract modpack content and stuff, don't symlink them into the mods folder
@@ -116,6 +147,17 @@ func (i *Instance) LinkDependencies() error {
 			continue
 		}
 
+		from, err := i.PackageCache().Path(dep)
+		if err != nil {
+			return fmt.Errorf("%s: %w", dep.Name, err)
+		}
+
+		// client only mods crash servers (and the other way around)
+		if environment := i.dependencyEnvironment(dep, from); environment != "" && environment != i.side() {
+			log.Printf("Skipping %s (only needed on the %s)", dep.Name, environment)
+			continue
+		}
+
 		copyFallback := false
 		// windows required admin permissions for symlinks (yea …)
 		if runtime.GOOS == "windows" {
@@ -140,9 +182,81 @@ func (i *Instance) LinkDependencies() error {
 	return nil
 }
 
+// side returns the environment this instance runs on (client or server)
+func (i *Instance) side() string {
+	if i.Environment == "" {
+		return manifest.EnvironmentClient
+	}
+	return i.Environment
+}
+
+// dependencyEnvironment returns the side the dependency is needed on or "" if it is needed on both.
+// If neither the manifest nor the lockfile know it, the fabric.mod.json of the jar is used.
+// That side is not written back to the lock entry, it is only recorded while resolving (see IndexDependencies)
+func (i *Instance) dependencyEnvironment(dep *manifest.DependencyLock, jar string) string {
+	if _, ok := i.Manifest.Environments[dep.Name]; ok || dep.Environment != "" {
+		return i.Manifest.EnvironmentOf(dep.Name, dep.Environment)
+	}
+
+	fabricManifest, err := fabric.ReadManifestFile(jar)
+	if err != nil {
+		// not a fabric mod or not readable, so we assume it is needed everywhere
+		return ""
+	}
+	return fabricManifest.Side()
+}
+
+// copyLocalDependency copies the file of a `file:` dependency into the package cache.
+// Relative paths are resolved from dir (the directory of the manifest)
+func copyLocalDependency(dep *manifest.DependencyLock, dir string, target string) error {
+	source, err := localFilePath(dep.URL)
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(source) {
+		source = filepath.Join(dir, source)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	hasher := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, hasher), in)
+	if cErr := out.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// the cache key contains the locked hash, so a changed file would be cached (and used) under the old one
+	if sum := fmt.Sprintf("%x", hasher.Sum(nil)); dep.Sha256 != "" && sum != dep.Sha256 {
+		os.Remove(target)
+		return &commands.CliError{
+			Text: fmt.Sprintf("%s changed since it was locked (%s)", dep.Name, source),
+			Suggestions: []string{
+				fmt.Sprintf("Run %s to update the lockfile", gchalk.Bold("minepkg lock")),
+			},
+		}
+	}
+	return nil
+}
+
 func (i *Instance) handleModpackDependencyCopy(dep *manifest.DependencyLock) error {
 
-	modpackPath := filepath.Join(i.PackageCacheDir(), dep.Name, dep.Version+".zip")
+	modpackPath, err := i.PackageCache().Path(dep)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dep.Name, err)
+	}
 	pkg, err := pack.Open(modpackPath)
 	if err != nil {
 		return err
@@ -158,17 +272,83 @@ func (i *Instance) EnsureDependencies(ctx context.Context) error {
 		return err
 	}
 
+	if err := i.downloadDependencies(ctx, missingFiles, i.DownloadProgress); err != nil {
+		return err
+	}
+
+	if err := i.LinkDependencies(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DownloadDependency downloads a single dependency into the package cache if it is not cached yet.
+// Used to download packages while the resolver is still running
+func (i *Instance) DownloadDependency(ctx context.Context, dep *manifest.DependencyLock) error {
+	if !dep.HasSource() {
+		return nil
+	}
+	_, err := i.PackageCache().Path(dep)
+	if !errors.Is(err, pkgcache.ErrNotCached) {
+		return err
+	}
+	return i.downloadDependencies(ctx, []*manifest.DependencyLock{dep}, nil)
+}
+
+// downloadDependencies downloads the given dependencies into the package cache
+func (i *Instance) downloadDependencies(ctx context.Context, missingFiles []*manifest.DependencyLock, onBytes func(int64, int64)) error {
+	cache := i.PackageCache()
+	if err := os.MkdirAll(cache.TempDir(), os.ModePerm); err != nil {
+		return err
+	}
+
+	// files are downloaded into the temporary directory of the cache and moved into the cache afterwards.
+	// Failed downloads keep their ".part" file there
 	mgr := downloadmgr.New()
+	mgr.OnBytes = onBytes
+	downloads := make(map[*manifest.DependencyLock]string, len(missingFiles))
 	for _, m := range missingFiles {
-		p := filepath.Join(i.PackageCacheDir(), m.Name, m.Version+m.FileExt())
-		mgr.Add(downloadmgr.NewHTTPItem(m.URL, p))
+		p := downloadPath(cache, m)
+		downloads[m] = p
+		// local files are copied instead of downloaded
+		if m.Provider == "file" {
+			if err := copyLocalDependency(m, i.Directory, p); err != nil {
+				return err
+			}
+			continue
+		}
+		if i.Offline {
+			return &ErrNotCached{Artifact: "package " + pkgcache.Key(m), Path: cache.Dir}
+		}
+		mgr.Add(newDependencyDownload(m, p))
 	}
 
 	if err := mgr.Start(ctx); err != nil {
 		return err
 	}
-	if err := i.LinkDependencies(); err != nil {
-		return err
+	for m, p := range downloads {
+		if _, err := cache.Store(m, p); err != nil {
+			return fmt.Errorf("failed to add %s to the package cache: %w", m.Name, err)
+		}
 	}
 	return nil
 }
+
+// newDependencyDownload returns the download of a lock entry. Every recorded hash is verified,
+// some sources (eg. maven repositories) only provide a sha1
+func newDependencyDownload(dep *manifest.DependencyLock, target string) *downloadmgr.HTTPItem {
+	sources := dep.Sources(IPFSGateway)
+	item := downloadmgr.NewHTTPItem(sources[0], target)
+	item.Mirrors = sources[1:]
+	item.Sha1 = dep.Sha1
+	item.Sha256 = dep.Sha256
+	item.Sha512 = dep.Sha512
+	return item
+}
+
+// downloadPath is where the dependency is downloaded to before it is stored in the package cache.
+// The path is the same for every run, so the ".part" file of an interrupted download is resumed later
+func downloadPath(cache *pkgcache.Cache, dep *manifest.DependencyLock) string {
+	key := sha256.Sum256([]byte(pkgcache.Key(dep)))
+	return filepath.Join(cache.TempDir(), fmt.Sprintf("%x%s", key, dep.FileExt()))
+}
@@ -0,0 +1,197 @@
+package instances
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/minepkg/minepkg/internals/commands"
+	"github.com/minepkg/minepkg/internals/downloadmgr"
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+func TestInstance_LinkDependencies_environment(t *testing.T) {
+	dir := t.TempDir()
+	instance := &Instance{
+		Directory: filepath.Join(dir, "instance"),
+		CacheDir:  filepath.Join(dir, "cache"),
+		Manifest:  manifest.New(),
+		Lockfile:  manifest.NewLockfile(),
+	}
+	instance.Manifest.Environments = map[string]string{"forced-everywhere": manifest.EnvironmentBoth}
+
+	locks := []*manifest.DependencyLock{
+		{Name: "minimap", Version: "1.0.0", Environment: manifest.EnvironmentClient},
+		{Name: "backups", Version: "1.0.0", Environment: manifest.EnvironmentServer},
+		{Name: "forced-everywhere", Version: "1.0.0", Environment: manifest.EnvironmentClient},
+		// the fabric.mod.json of this jar is used
+		{Name: "test-utils", Version: "1.0.0"},
+	}
+	for _, lock := range locks {
+		lock.Type = manifest.DependencyLockTypeMod
+		lock.URL = "https://example.com/" + lock.Name + ".jar"
+		instance.Lockfile.AddDependency(lock)
+
+		src := filepath.Join(dir, lock.Name+".jar")
+		if err := copyFileContents("../../testdata/fake-testmod-0.0.1.jar", src); err != nil {
+			t.Fatal(err)
+		}
+		if lock.Name == "test-utils" {
+			jar := testModJar(t, map[string][]byte{"fabric.mod.json": []byte(`{"schemaVersion": 1, "id": "test-utils", "version": "1.0.0", "environment": "server"}`)})
+			if err := os.WriteFile(src, jar, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// every jar needs to be unique, otherwise the cache stores it only once
+		f, _ := os.OpenFile(src, os.O_APPEND|os.O_WRONLY, 0644)
+		f.WriteString(lock.Name)
+		f.Close()
+		if _, err := instance.PackageCache().Store(lock, src); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	linked := func() []string {
+		if err := instance.LinkDependencies(); err != nil {
+			t.Fatal(err)
+		}
+		entries, _ := os.ReadDir(instance.ModsDir())
+		names := make([]string, len(entries))
+		for i, entry := range entries {
+			names[i] = entry.Name()
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	before := instance.Lockfile.String()
+	if got := linked(); len(got) != 2 || got[1] != "minimap-1.0.0.jar" {
+		t.Errorf("expected the client to skip the server only mods, got %v", got)
+	}
+
+	instance.Environment = manifest.EnvironmentServer
+	expected := []string{"backups-1.0.0.jar", "forced-everywhere-1.0.0.jar", "test-utils-1.0.0.jar"}
+	if got := linked(); len(got) != 3 || got[0] != expected[0] || got[1] != expected[1] || got[2] != expected[2] {
+		t.Errorf("expected %v on the server, got %v", expected, got)
+	}
+
+	// linking does not depend on the cache state, so it must not change the lockfile
+	if instance.Lockfile.String() != before {
+		t.Error("expected LinkDependencies to leave the lockfile untouched")
+	}
+}
+
+func TestInstance_downloadDependencies_resume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	ctx, interrupt := context.WithCancel(context.Background())
+	var gotRange string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Accept-Ranges", "bytes")
+		gotRange = r.Header.Get("Range")
+		if gotRange != "" {
+			http.ServeContent(w, r, "mod.jar", time.Time{}, bytes.NewReader(content))
+			return
+		}
+		// the first run is interrupted after half of the file
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Write(content[:len(content)/2])
+		w.(http.Flusher).Flush()
+		time.AfterFunc(50*time.Millisecond, interrupt)
+		panic(http.ErrAbortHandler)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	instance := &Instance{CacheDir: filepath.Join(dir, "cache")}
+	lock := &manifest.DependencyLock{Name: "big-mod", Version: "1.0.0", Type: manifest.DependencyLockTypeMod, URL: server.URL + "/big-mod.jar"}
+
+	if err := instance.downloadDependencies(ctx, []*manifest.DependencyLock{lock}, nil); err == nil {
+		t.Fatal("expected the interrupted download to fail")
+	}
+	if _, err := os.Stat(downloadPath(instance.PackageCache(), lock) + ".part"); err != nil {
+		t.Fatalf("expected the partial download to be kept: %s", err)
+	}
+
+	if err := instance.downloadDependencies(context.Background(), []*manifest.DependencyLock{lock}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("bytes=%d-", len(content)/2); gotRange != want {
+		t.Errorf("expected the second run to resume with %q, got %q", want, gotRange)
+	}
+	cached, err := instance.PackageCache().Path(lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if downloaded, _ := os.ReadFile(cached); !bytes.Equal(downloaded, content) {
+		t.Error("the resumed download does not match the file")
+	}
+}
+
+func Test_newDependencyDownload_sha1Only(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tampered jar"))
+	}))
+	defer server.Close()
+
+	// maven artifacts often only have a .sha1 sidecar
+	lock := &manifest.DependencyLock{
+		Name:     "net.example:lib",
+		Version:  "1.0.0",
+		Provider: "maven",
+		URL:      server.URL + "/lib-1.0.0.jar",
+		Sha1:     fmt.Sprintf("%x", sha1.Sum([]byte("original jar"))),
+	}
+	item := newDependencyDownload(lock, filepath.Join(t.TempDir(), "lib.jar"))
+
+	var invalidSha *downloadmgr.ErrInvalidSha
+	if err := item.Download(context.Background()); !errors.As(err, &invalidSha) || invalidSha.Algorithm != "sha1" {
+		t.Errorf("expected the sha1 to be verified, got %v", err)
+	}
+}
+
+func TestInstance_downloadDependencies_localFile(t *testing.T) {
+	dir := t.TempDir()
+	instance := &Instance{Directory: dir, CacheDir: filepath.Join(dir, "cache")}
+	if err := copyFileContents("../../testdata/fake-testmod-0.0.1.jar", filepath.Join(dir, "mylib.jar")); err != nil {
+		t.Fatal(err)
+	}
+	// the path is relative to the manifest, not to the working directory
+	t.Chdir(t.TempDir())
+
+	res, err := newFileResult("mylib", "local", filepath.Join(dir, "mylib.jar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock := &manifest.DependencyLock{Name: "mylib", Version: "local-1", Type: manifest.DependencyLockTypeMod, Provider: "file", URL: "file:mylib.jar", Sha256: res.sha256}
+	if err := instance.downloadDependencies(context.Background(), []*manifest.DependencyLock{lock}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := instance.PackageCache().Path(lock); err != nil {
+		t.Errorf("expected the local file to be cached: %s", err)
+	}
+
+	// the jar was changed after locking
+	f, _ := os.OpenFile(filepath.Join(dir, "mylib.jar"), os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("changed")
+	f.Close()
+	changed := &manifest.DependencyLock{Name: "mylib", Version: "local-2", Type: manifest.DependencyLockTypeMod, Provider: "file", URL: "file:mylib.jar", Sha256: res.sha256}
+	err = instance.downloadDependencies(context.Background(), []*manifest.DependencyLock{changed}, nil)
+	var cliErr *commands.CliError
+	if !errors.As(err, &cliErr) {
+		t.Fatalf("expected the changed file to be rejected, got %v", err)
+	}
+	if _, err := instance.PackageCache().Path(changed); err == nil {
+		t.Error("expected the changed file not to be cached")
+	}
+}
@@ -115,7 +115,7 @@ func (i *Instance) FindModJar() ([]MatchedJar, error) {
 }
 
 func (i *Instance) findModJarCandidatesFromPattern(pattern string) ([]MatchedJar, error) {
-	matches, err := filepath.Glob(pattern)
+	matches, err := filepath.Glob(i.projectPath(pattern))
 	if err != nil {
 		return nil, err
 	}
@@ -144,7 +144,8 @@ func (i *Instance) findModJarCandidatesFromPattern(pattern string) ([]MatchedJar
 }
 
 func (i *Instance) findModJarCandidates() ([]MatchedJar, error) {
-	files, err := ioutil.ReadDir("./build/libs")
+	libsDir := i.projectPath("./build/libs")
+	files, err := ioutil.ReadDir(libsDir)
 	if err != nil {
 		return nil, ErrNoBuildFiles
 	}
@@ -156,7 +157,7 @@ func (i *Instance) findModJarCandidates() ([]MatchedJar, error) {
 	jars := make([]MatchedJar, len(filtered))
 	for ix, file := range filtered {
 		jars[ix] = MatchedJar{
-			path: filepath.Join("./build/libs", file.Name()),
+			path: filepath.Join(libsDir, file.Name()),
 			stat: file,
 		}
 	}
@@ -164,6 +165,18 @@ func (i *Instance) findModJarCandidates() ([]MatchedJar, error) {
 	return jars, nil
 }
 
+// projectPath returns the given path relative to the instance directory.
+// paths are returned unchanged if this instance is the working directory
+func (i *Instance) projectPath(path string) string {
+	if filepath.IsAbs(path) || i.Directory == "" {
+		return path
+	}
+	if wd, err := os.Getwd(); err == nil && wd == i.Directory {
+		return path
+	}
+	return filepath.Join(i.Directory, path)
+}
+
 // preFilteredFiles filters out common dev files (dev, sources)
 func preFilteredFiles(files []fs.FileInfo) []fs.FileInfo {
 	filtered := []fs.FileInfo{}
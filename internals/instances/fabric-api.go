@@ -2,10 +2,8 @@ package instances
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
+
+	"github.com/minepkg/minepkg/internals/metacache"
 )
 
 type fabricLoaderVersion struct {
@@ -25,49 +23,20 @@ type fabricMappingVersion struct {
 	Stable      bool   `json:"stable"`
 }
 
-func getFabricLoaderVersions(ctx context.Context) ([]fabricLoaderVersion, error) {
+func getFabricLoaderVersions(ctx context.Context, cache *metacache.Cache) ([]fabricLoaderVersion, error) {
 	loaders := make([]fabricLoaderVersion, 0)
-	res, err := fabricGet(ctx, "https://meta.fabricmc.net/v1/versions/loader")
-	if err != nil {
-		return nil, err
-	}
-	buf, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(buf, &loaders); err != nil {
+	if err := cache.GetJSON(ctx, "https://meta.fabricmc.net/v1/versions/loader", &loaders); err != nil {
 		return nil, err
 	}
 
 	return loaders, nil
 }
 
-func getFabricMappingVersions(ctx context.Context) ([]fabricMappingVersion, error) {
+func getFabricMappingVersions(ctx context.Context, cache *metacache.Cache) ([]fabricMappingVersion, error) {
 	loaders := make([]fabricMappingVersion, 0)
-	res, err := fabricGet(ctx, "https://meta.fabricmc.net/v1/versions/mappings")
-	if err != nil {
-		return nil, err
-	}
-	buf, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(buf, &loaders); err != nil {
+	if err := cache.GetJSON(ctx, "https://meta.fabricmc.net/v1/versions/mappings", &loaders); err != nil {
 		return nil, err
 	}
 
 	return loaders, nil
 }
-
-func fabricGet(ctx context.Context, url string) (*http.Response, error) {
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("User-Agent", "minepkg (https://github.com/minepkg/minepkg)")
-	req.Header.Set("Content-Type", "application/json")
-	res, err := http.DefaultClient.Do(req.WithContext(ctx))
-	if err == nil && res.StatusCode != 200 {
-		return res, fmt.Errorf("fabric meta API did respond with unexpected status %s", res.Status)
-	}
-	return res, err
-}
@@ -0,0 +1,272 @@
+package instances
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/minepkg/minepkg/internals/pkgid"
+	"github.com/minepkg/minepkg/internals/provider"
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+var (
+	ErrFileDependencyNotFound = errors.New("local dependency does not exist")
+	ErrNotAFileURL            = errors.New("not a file:// url")
+)
+
+// FileProvider resolves `file:` dependencies. These can point to a jar file
+// or to a directory containing a minepkg.toml (a local project).
+// Relative paths of the root manifest are resolved from its directory (see provider.Request),
+// relative paths of local projects from their own directory.
+type FileProvider struct {
+	// BuildOutput receives the output of build commands. Output is discarded if nil
+	BuildOutput io.Writer
+
+	// buildLock makes sure that only one project is built at a time
+	buildLock sync.Mutex
+}
+
+type fileResult struct {
+	name        string
+	versionName string
+	path        string
+	// lockPath is the path recorded in the lockfile. It is relative to the manifest, unless an absolute path was requested
+	lockPath     string
+	sha256       string
+	packageType  string
+	dependencies []*manifest.InterpretedDependency
+	// incompatibilities are those of the local project (if any)
+	incompatibilities []*manifest.InterpretedDependency
+}
+
+func (f *fileResult) Lock() *manifest.DependencyLock {
+	lock := &manifest.DependencyLock{
+		Name:        f.name,
+		Version:     "local",
+		VersionName: f.versionName,
+		Type:        f.packageType,
+		Provider:    "file",
+		Sha256:      f.sha256,
+	}
+
+	// projects without jar (eg. modpacks) only contribute dependencies
+	if f.path != "" {
+		// the content hash is part of the version, so changed files end up in a new cache entry
+		lock.Version = "local-" + f.sha256[:16]
+		lock.URL = fileURL(f.lockPath)
+	}
+
+	return lock
+}
+
+func (f *fileResult) Dependencies() []*manifest.InterpretedDependency {
+	return f.dependencies
+}
+
+func (f *fileResult) Incompatibilities() []*manifest.InterpretedDependency {
+	return f.incompatibilities
+}
+
+func NewFileProvider() *FileProvider {
+	return &FileProvider{}
+}
+
+func (f *FileProvider) Name() string { return "file" }
+
+func (f *FileProvider) Resolve(ctx context.Context, request *provider.Request) (provider.Result, error) {
+	path := request.Dependency.Version
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(request.Dir, path)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrFileDependencyNotFound, path)
+		}
+		return nil, err
+	}
+
+	var result *fileResult
+	if !stat.IsDir() {
+		result, err = newFileResult(request.Dependency.Name, stat.Name(), path)
+	} else {
+		result, err = f.resolveProject(ctx, request.Dependency.Name, path, request.Dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the lockfile is committed, so it should not contain paths of this machine
+	result.lockPath = result.path
+	if result.path != "" && !filepath.IsAbs(request.Dependency.Version) {
+		result.lockPath = relativePath(request.Dir, result.path)
+	}
+	return result, nil
+}
+
+// resolveProject builds the project in dir (if it has a build command) and returns its jar.
+// Relative paths of its local dependencies are converted to be relative to rootDir
+func (f *FileProvider) resolveProject(ctx context.Context, name string, dir string, rootDir string) (*fileResult, error) {
+	project, err := NewFromDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read local project %s: %w", dir, err)
+	}
+
+	dependencies := make([]*manifest.InterpretedDependency, 0, len(project.Manifest.Dependencies))
+	for _, dependency := range project.Manifest.InterpretedDependencies() {
+		// paths of local dependencies are relative to the project, not to us
+		if dependency.Provider == "file" && !filepath.IsAbs(dependency.Source) {
+			dependency.Source = relativePath(rootDir, filepath.Join(dir, dependency.Source))
+			dependency.ID = &pkgid.ID{Provider: "file", Name: dependency.ID.Name, Version: dependency.Source}
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	versionName := project.Manifest.Package.Version
+	if versionName == "" {
+		versionName = "local"
+	}
+
+	if project.Manifest.Package.Type == manifest.TypeModpack {
+		return &fileResult{
+			name:              name,
+			versionName:       versionName,
+			packageType:       manifest.DependencyLockTypeModpack,
+			dependencies:      dependencies,
+			incompatibilities: project.Manifest.InterpretedIncompatibilities(),
+		}, nil
+	}
+
+	if project.Manifest.Dev.BuildCommand != "" {
+		if err := f.build(ctx, project); err != nil {
+			return nil, fmt.Errorf("could not build local project %s: %w", dir, err)
+		}
+	}
+
+	jars, err := project.FindModJar()
+	if err != nil {
+		return nil, fmt.Errorf("could not find jar of local project %s: %w", dir, err)
+	}
+
+	result, err := newFileResult(name, versionName, jars[0].Path())
+	if err != nil {
+		return nil, err
+	}
+	result.dependencies = dependencies
+	result.incompatibilities = project.Manifest.InterpretedIncompatibilities()
+
+	return result, nil
+}
+
+func (f *FileProvider) build(ctx context.Context, project *Instance) error {
+	f.buildLock.Lock()
+	defer f.buildLock.Unlock()
+
+	// output is kept to show it in case the build fails
+	var output bytes.Buffer
+	var out io.Writer = &output
+	if f.BuildOutput != nil {
+		out = io.MultiWriter(&output, f.BuildOutput)
+	}
+
+	build := project.BuildMod()
+	build.Dir = project.Directory
+	build.Stdout = out
+	build.Stderr = out
+
+	// only kill the build if the context was cancelled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			if build.Process != nil {
+				build.Process.Kill()
+			}
+		case <-done:
+		}
+	}()
+
+	if err := build.Run(); err != nil {
+		return fmt.Errorf("%w\n%s", err, output.String())
+	}
+
+	return nil
+}
+
+func newFileResult(name string, versionName string, path string) (*fileResult, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return nil, err
+	}
+
+	return &fileResult{
+		name:        name,
+		versionName: versionName,
+		path:        path,
+		sha256:      fmt.Sprintf("%x", hasher.Sum(nil)),
+		packageType: manifest.DependencyLockTypeMod,
+	}, nil
+}
+
+// relativePath returns the path relative to base or the working directory if base is empty (if possible).
+// this way the same project required by the root manifest and a local project yields the same path
+func relativePath(base string, path string) string {
+	base, err := filepath.Abs(base)
+	if err != nil {
+		return path
+	}
+	relative, err := filepath.Rel(base, path)
+	if err != nil {
+		return path
+	}
+	return relative
+}
+
+// fileURL returns a `file://` url for absolute paths and a `file:` url for relative ones
+func fileURL(path string) string {
+	if !filepath.IsAbs(path) {
+		return "file:" + filepath.ToSlash(path)
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// localFilePath returns the path of a `file://` url or the relative path of a `file:` url
+func localFilePath(fileURL string) (string, error) {
+	if !strings.HasPrefix(fileURL, "file://") {
+		if relative, ok := strings.CutPrefix(fileURL, "file:"); ok && relative != "" {
+			return filepath.FromSlash(relative), nil
+		}
+		return "", ErrNotAFileURL
+	}
+	parsed, err := url.Parse(fileURL)
+	if err != nil {
+		return "", err
+	}
+	return filepath.FromSlash(parsed.Path), nil
+}
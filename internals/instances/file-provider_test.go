@@ -0,0 +1,109 @@
+package instances
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minepkg/minepkg/internals/pkgid"
+	"github.com/minepkg/minepkg/internals/provider"
+)
+
+const testLocalProjectManifest = `manifestVersion = 0
+
+[package]
+  type = "mod"
+  name = "mylib"
+  version = "1.2.0"
+
+[requirements]
+  minecraft = "~1.20.1"
+  fabricLoader = "*"
+
+[dependencies]
+  other-lib = "file:../other-lib"
+  fabric = "*"
+
+[dev]
+  jar = "out/*.jar"
+`
+
+func TestFileProvider_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "mylib")
+	if err := os.MkdirAll(filepath.Join(project, "out"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "minepkg.toml"), []byte(testLocalProjectManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := copyFileContents("../../testdata/fake-testmod-0.0.1.jar", filepath.Join(project, "out/mylib.jar")); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewFileProvider()
+	res, err := p.Resolve(context.Background(), &provider.Request{
+		Dependency: pkgid.Parse("file:" + project),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lock := res.Lock()
+	if lock.VersionName != "1.2.0" || lock.Sha256 == "" || !strings.HasPrefix(lock.Version, "local-") {
+		t.Errorf("unexpected lock %+v", lock)
+	}
+
+	source, err := localFilePath(lock.URL)
+	if err != nil || source != filepath.Join(project, "out/mylib.jar") {
+		t.Errorf("expected url to point to the jar, got %s (%v)", lock.URL, err)
+	}
+
+	for _, dependency := range res.Dependencies() {
+		if dependency.Name != "other-lib" {
+			continue
+		}
+		resolved, _ := filepath.Abs(dependency.Source)
+		if resolved != filepath.Join(dir, "other-lib") {
+			t.Errorf("expected other-lib to be relative to the project, got %s", dependency.Source)
+		}
+		return
+	}
+	t.Error("expected other-lib to be a dependency")
+}
+
+func TestFileProvider_Resolve_relativeToManifest(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "mylib")
+	if err := os.MkdirAll(filepath.Join(project, "out"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "minepkg.toml"), []byte(testLocalProjectManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := copyFileContents("../../testdata/fake-testmod-0.0.1.jar", filepath.Join(project, "out/mylib.jar")); err != nil {
+		t.Fatal(err)
+	}
+
+	// eg. `minepkg launch` in a subdirectory of the modpack
+	t.Chdir(t.TempDir())
+
+	res, err := NewFileProvider().Resolve(context.Background(), &provider.Request{
+		Dependency: pkgid.Parse("file:mylib"),
+		Dir:        dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if url := res.Lock().URL; url != "file:mylib/out/mylib.jar" {
+		t.Errorf("expected the path to be relative to the manifest, got %s", url)
+	}
+	for _, dependency := range res.Dependencies() {
+		if dependency.Name == "other-lib" && dependency.Source != "other-lib" {
+			t.Errorf("expected other-lib to be relative to the manifest, got %s", dependency.Source)
+		}
+	}
+}
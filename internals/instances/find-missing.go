@@ -22,6 +22,10 @@ func (i *Instance) FindMissingLibraries(man *minecraft.LaunchManifest) ([]minecr
 		if _, err := os.Stat(path); err == nil {
 			continue
 		}
+		// created by the mod loader installer, see InstallModLoader
+		if lib.IsLocal() {
+			continue
+		}
 
 		missing = append(missing, lib)
 	}
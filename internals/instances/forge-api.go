@@ -0,0 +1,96 @@
+package instances
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// forgeMaven is the maven repository Forge is published to
+	forgeMaven = "https://maven.minecraftforge.net/net/minecraftforge/forge"
+	// forgePromotions lists the recommended and latest Forge version of every Minecraft version
+	forgePromotions = "https://files.minecraftforge.net/net/minecraftforge/forge/promotions_slim.json"
+)
+
+// getForgeVersions returns all published Forge versions in the "[minecraft]-[forge]" format (eg. 1.20.1-47.2.0)
+func getForgeVersions(ctx context.Context) ([]string, error) {
+	return getMavenVersions(ctx, forgeMaven+"/maven-metadata.xml")
+}
+
+// getForgePromotions returns the promoted Forge versions (eg. "1.20.1-recommended": "47.2.0")
+func getForgePromotions(ctx context.Context) (map[string]string, error) {
+	res, err := metaGet(ctx, forgePromotions)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	promotions := struct {
+		Promos map[string]string `json:"promos"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&promotions); err != nil {
+		return nil, fmt.Errorf("invalid Forge promotions: %w", err)
+	}
+	return promotions.Promos, nil
+}
+
+// forgeInstallerURL returns the download URL of the installer jar for a "[minecraft]-[forge]" version
+func forgeInstallerURL(version string) string {
+	return fmt.Sprintf("%s/%s/forge-%s-installer.jar", forgeMaven, version, version)
+}
+
+// compareForgeVersions compares Forge versions with any amount of numbers (eg. 14.23.5.2859).
+// Returns a negative number if a is older than b, a positive number if it is newer and 0 if they are equal
+func compareForgeVersions(a string, b string) int {
+	partsA := strings.Split(strings.SplitN(a, "-", 2)[0], ".")
+	partsB := strings.Split(strings.SplitN(b, "-", 2)[0], ".")
+	for n := 0; n < len(partsA) || n < len(partsB); n++ {
+		var numA, numB int
+		if n < len(partsA) {
+			numA, _ = strconv.Atoi(partsA[n])
+		}
+		if n < len(partsB) {
+			numB, _ = strconv.Atoi(partsB[n])
+		}
+		if numA != numB {
+			return numA - numB
+		}
+	}
+	return 0
+}
+
+// getMavenVersions returns the versions listed in a maven-metadata.xml file
+func getMavenVersions(ctx context.Context, url string) ([]string, error) {
+	res, err := metaGet(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	metadata := struct {
+		Versions []string `xml:"versioning>versions>version"`
+	}{}
+	if err := xml.NewDecoder(res.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("invalid maven-metadata.xml: %w", err)
+	}
+	return metadata.Versions, nil
+}
+
+func metaGet(ctx context.Context, url string) (*http.Response, error) {
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req.Header.Set("User-Agent", "minepkg (https://github.com/minepkg/minepkg)")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != 200 {
+		res.Body.Close()
+		return nil, fmt.Errorf("%s did respond with unexpected status %s", url, res.Status)
+	}
+	return res, nil
+}
@@ -0,0 +1,41 @@
+package instances
+
+import "testing"
+
+func Test_compareForgeVersions(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"47.2.0", "47.2.0", 0},
+		{"47.2.10", "47.2.9", 1},
+		{"14.23.5.2859", "14.23.5.2860", -1},
+		{"14.23.5.2859", "14.23.5", 1},
+		{"10.13.4.1614-1.7.10", "10.13.4.1614", 0},
+	}
+	for _, tt := range tests {
+		got := compareForgeVersions(tt.a, tt.b)
+		if (got < 0 && tt.want >= 0) || (got > 0 && tt.want <= 0) || (got == 0 && tt.want != 0) {
+			t.Errorf("compareForgeVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func Test_looseSemver(t *testing.T) {
+	tests := map[string]string{
+		"47.2.0":              "47.2.0",
+		"14.23.5.2859":        "14.23.5",
+		"10.13.4.1614-1.7.10": "10.13.4",
+	}
+	for version, want := range tests {
+		got, err := looseSemver(version)
+		if err != nil {
+			t.Errorf("looseSemver(%q) failed: %s", version, err)
+			continue
+		}
+		if got.String() != want {
+			t.Errorf("looseSemver(%q) = %s, want %s", version, got, want)
+		}
+	}
+}
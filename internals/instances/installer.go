@@ -0,0 +1,254 @@
+package instances
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/minepkg/minepkg/internals/downloadmgr"
+	"github.com/minepkg/minepkg/internals/forge"
+	"github.com/minepkg/minepkg/internals/minecraft"
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+// loaderInstaller is the installer jar of a mod loader (Forge or NeoForge)
+type loaderInstaller struct {
+	// name of the mod loader
+	name string
+	// coordinate is the maven coordinate of the installer jar (it is stored in the libraries dir)
+	coordinate string
+	url        string
+}
+
+// loaderInstaller returns the installer of the locked mod loader or nil if the platform does not use one
+func (i *Instance) loaderInstaller() *loaderInstaller {
+	switch {
+	case i.Platform() == PlatformNeoForge && i.Lockfile.NeoForge != nil:
+		version := i.Lockfile.NeoForge.NeoForgeLoader
+		return &loaderInstaller{
+			name:       "NeoForge",
+			coordinate: "net.neoforged:neoforge:" + version + ":installer",
+			url:        neoForgeInstallerURL(version),
+		}
+	case i.Platform() == PlatformForge && i.Lockfile.Forge != nil:
+		version := i.Lockfile.Forge.Minecraft + "-" + i.Lockfile.Forge.ForgeLoader
+		return &loaderInstaller{
+			name:       "Forge",
+			coordinate: "net.minecraftforge:forge:" + version + ":installer",
+			url:        forgeInstallerURL(version),
+		}
+	default:
+		return nil
+	}
+}
+
+// InstallModLoader runs the installer of mod loaders that need to patch Minecraft before launching (Forge and NeoForge).
+// The Minecraft jar and libraries of the launch manifest have to be downloaded already.
+// It returns the launch manifest that should be used to launch the instance. This is
+// `launchManifest` unless a server is launched with a different main class
+func (i *Instance) InstallModLoader(ctx context.Context, launchManifest *minecraft.LaunchManifest, java string, server bool) (*minecraft.LaunchManifest, error) {
+	installer := i.loaderInstaller()
+	if installer == nil {
+		return launchManifest, nil
+	}
+
+	installerPath, err := i.downloadInstaller(ctx, installer)
+	if err != nil {
+		return nil, err
+	}
+	jar, err := forge.OpenInstaller(installerPath)
+	if err != nil {
+		return nil, err
+	}
+	defer jar.Close()
+
+	side := manifest.EnvironmentClient
+	var serverArgs *forge.ServerArgs
+	if server {
+		side = manifest.EnvironmentServer
+		serverArgs, err = jar.ServerArgs(i.LibrariesDir())
+		if errors.Is(err, forge.ErrNoServerArgs) {
+			return nil, fmt.Errorf(
+				"%w: %s servers for Minecraft %s can not be launched yet",
+				ErrLaunchNotImplemented,
+				installer.name,
+				i.Lockfile.MinecraftVersion(),
+			)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// written after all processors ran successfully
+	marker := filepath.Join(i.VersionsDir(), i.Lockfile.McManifestName(), ".installed-"+side)
+	if _, err := os.Stat(marker); err != nil {
+		if err := i.runInstaller(ctx, installer, jar, launchManifest, java, side); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(marker), os.ModePerm); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(marker, []byte(installer.coordinate+"\n"), 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	if serverArgs == nil {
+		return launchManifest, nil
+	}
+
+	serverManifest := *launchManifest
+	serverManifest.MainClass = serverArgs.MainClass
+	serverManifest.Arguments = &minecraft.Arguments{}
+	for _, arg := range serverArgs.JVM {
+		serverManifest.Arguments.JVM = append(serverManifest.Arguments.JVM, minecraft.Argument{
+			ActualArgument: minecraft.ActualArgument{Value: []string{arg}},
+		})
+	}
+	serverManifest.ServerArgs = serverArgs.Game
+	return &serverManifest, nil
+}
+
+func (i *Instance) runInstaller(ctx context.Context, installer *loaderInstaller, jar *forge.Installer, launchManifest *minecraft.LaunchManifest, java string, side string) error {
+	if err := jar.ExtractLibraries(i.LibrariesDir()); err != nil {
+		return err
+	}
+	if err := i.downloadLibraries(ctx, jar.Profile.Libraries); err != nil {
+		return fmt.Errorf("failed to download %s installer libraries: %w", installer.name, err)
+	}
+
+	minecraftJar := filepath.Join(i.VersionsDir(), launchManifest.MinecraftVersion(), launchManifest.JarName())
+	// the server is patched instead of the client
+	if side == manifest.EnvironmentServer {
+		minecraftJar = filepath.Join(i.VersionsDir(), launchManifest.MinecraftVersion(), launchManifest.MinecraftVersion()+"-server.jar")
+		if err := i.downloadServerJar(ctx, launchManifest, minecraftJar); err != nil {
+			return err
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "minepkg-installer-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	log.Printf("Running %s processors (%s)", installer.name, side)
+	return jar.RunProcessors(ctx, &forge.InstallOptions{
+		Java:         java,
+		Side:         side,
+		LibrariesDir: i.LibrariesDir(),
+		MinecraftJar: minecraftJar,
+		TempDir:      tmpDir,
+		Output:       log.Writer(),
+	})
+}
+
+// fetchInstallerManifest returns the launch manifest from the version.json of the mod loader installer
+func (i *Instance) fetchInstallerManifest() (*minecraft.LaunchManifest, error) {
+	installer := i.loaderInstaller()
+	if installer == nil {
+		return nil, ErrLaunchNotImplemented
+	}
+
+	launchManifest := minecraft.LaunchManifest{}
+	version := i.Lockfile.McManifestName()
+	file := filepath.Join(i.VersionsDir(), version, version+".json")
+
+	// cached
+	if rawMan, err := os.ReadFile(file); err == nil {
+		if err := json.Unmarshal(rawMan, &launchManifest); err == nil {
+			log.Printf("Using cached %s manifest %s", installer.name, file)
+			return &launchManifest, nil
+		}
+		// corrupted manifest, read it from the installer again
+	}
+
+	installerPath, err := i.downloadInstaller(context.TODO(), installer)
+	if err != nil {
+		return nil, err
+	}
+	jar, err := forge.OpenInstaller(installerPath)
+	if err != nil {
+		return nil, err
+	}
+	defer jar.Close()
+
+	// some libraries are only bundled with the installer and can not be downloaded
+	if err := jar.ExtractLibraries(i.LibrariesDir()); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(file, jar.RawLaunchManifest, 0666); err != nil {
+		return nil, err
+	}
+
+	return jar.LaunchManifest, nil
+}
+
+// downloadInstaller returns the path of the installer jar and downloads it if needed
+func (i *Instance) downloadInstaller(ctx context.Context, installer *loaderInstaller) (string, error) {
+	target := filepath.Join(i.LibrariesDir(), forge.ArtifactPath(installer.coordinate))
+	if _, err := os.Stat(target); err == nil {
+		return target, nil
+	}
+	if i.Offline {
+		return "", &ErrNotCached{Artifact: installer.name + " installer", Path: target}
+	}
+
+	log.Println("Downloading", installer.name, "installer from", installer.url)
+	mgr := downloadmgr.New()
+	mgr.Add(downloadmgr.NewHTTPItem(installer.url, target))
+	if err := mgr.Start(ctx); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// downloadServerJar downloads the vanilla server jar that is patched by the installer
+func (i *Instance) downloadServerJar(ctx context.Context, launchManifest *minecraft.LaunchManifest, target string) error {
+	if _, err := os.Stat(target); err == nil {
+		return nil
+	}
+	if i.Offline {
+		return &ErrNotCached{Artifact: "Minecraft server jar", Path: target}
+	}
+	if launchManifest.Downloads == nil || launchManifest.Downloads.Server.URL == "" {
+		return fmt.Errorf("there is no server jar for Minecraft %s", launchManifest.MinecraftVersion())
+	}
+
+	mgr := downloadmgr.New()
+	item := downloadmgr.NewHTTPItem(launchManifest.Downloads.Server.URL, target)
+	item.Sha1 = launchManifest.Downloads.Server.Sha1
+	mgr.Add(item)
+	return mgr.Start(ctx)
+}
+
+// downloadLibraries downloads all missing libraries into the libraries directory.
+// Libraries without a download url need to exist already (they are bundled with installers)
+func (i *Instance) downloadLibraries(ctx context.Context, libraries []minecraft.Library) error {
+	mgr := downloadmgr.New()
+	for _, lib := range minecraft.RequiredLibraries(libraries) {
+		target := filepath.Join(i.LibrariesDir(), lib.Filepath())
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+		if lib.IsLocal() {
+			return fmt.Errorf("library %s is missing", lib.Name)
+		}
+		if i.Offline {
+			return &ErrNotCached{Artifact: "library " + lib.Name, Path: target}
+		}
+		item := downloadmgr.NewHTTPItem(lib.DownloadURL(), target)
+		item.Sha1 = lib.DownloadSha1()
+		mgr.Add(item)
+	}
+	return mgr.Start(ctx)
+}
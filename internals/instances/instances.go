@@ -3,13 +3,16 @@ package instances
 import (
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/jwalton/gchalk"
 	"github.com/minepkg/minepkg/internals/commands"
+	"github.com/minepkg/minepkg/internals/metacache"
 	"github.com/minepkg/minepkg/internals/minecraft"
+	"github.com/minepkg/minepkg/internals/pkgcache"
 	"github.com/minepkg/minepkg/internals/provider"
 
 	"github.com/minepkg/minepkg/internals/api"
@@ -24,6 +27,13 @@ var (
 	PlatformFabric uint8 = 2
 	// PlatformForge is forge minecraft instance
 	PlatformForge uint8 = 3
+	// PlatformQuilt is a quilt minecraft instance
+	PlatformQuilt uint8 = 4
+	// PlatformNeoForge is a NeoForge minecraft instance
+	PlatformNeoForge uint8 = 5
+
+	// IPFSGateway is used to download packages by their IPFS hash if all other sources fail
+	IPFSGateway = "https://ipfs.io/ipfs/"
 
 	// ErrNoInstance is returned if no mc instance was found
 	ErrNoInstance = &commands.CliError{
@@ -58,12 +68,21 @@ type Instance struct {
 	MinepkgAPI      *api.MinepkgClient
 	AuthCredentials *LaunchCredentials
 	ProviderStore   *provider.Store
+	// Offline disables all network access. Everything has to be in the lockfile & cache already
+	Offline bool
+	// Environment is the side this instance runs on. Can be manifest.EnvironmentServer, defaults to the client.
+	// Dependencies that are only needed on the other side are not linked
+	Environment string
+	// DownloadProgress is called periodically while packages are downloaded
+	DownloadProgress func(transferred int64, total int64)
 
 	isFromWd                     bool
 	launchManifest               *minecraft.LaunchManifest
 	launchCmd                    string
 	lockfileNeedsRenameMigration bool
 	nativesDir                   string
+	packageCache                 *pkgcache.Cache
+	metaCache                    *metacache.Cache
 }
 
 // LaunchCmd returns the cmd used to launch minecraft (if started)
@@ -93,11 +112,39 @@ func (i *Instance) InstancesDir() string {
 	return filepath.Join(i.GlobalDir, "instances")
 }
 
-// PackageCacheDir returns the path to the cache directory. contains downloaded packages (mods & modpacks)
+// PackageCacheDir returns the path to the package cache directory. contains downloaded packages (mods & modpacks)
 func (i *Instance) PackageCacheDir() string {
+	return filepath.Join(i.CacheDir, "packages")
+}
+
+// LegacyPackageCacheDir returns the path of the old package cache that stored files by name & version
+func (i *Instance) LegacyPackageCacheDir() string {
 	return filepath.Join(i.CacheDir, "cache")
 }
 
+// PackageCache returns the content addressed package cache
+func (i *Instance) PackageCache() *pkgcache.Cache {
+	if i.packageCache == nil {
+		i.packageCache = pkgcache.New(i.PackageCacheDir())
+	}
+	return i.packageCache
+}
+
+// MetaCacheDir returns the path to the metadata cache directory. contains responses of the Mojang, Fabric & Java APIs
+func (i *Instance) MetaCacheDir() string {
+	return filepath.Join(i.CacheDir, "meta")
+}
+
+// MetaCache returns the cache for metadata API responses. It only serves cached responses in offline mode
+func (i *Instance) MetaCache() *metacache.Cache {
+	if i.metaCache == nil {
+		i.metaCache = metacache.New(i.MetaCacheDir())
+		i.metaCache.Header = http.Header{"User-Agent": []string{"minepkg (https://github.com/minepkg/minepkg)"}}
+	}
+	i.metaCache.Offline = i.Offline
+	return i.metaCache
+}
+
 // JavaDir returns the path for local java binaries
 func (i *Instance) JavaDir() string {
 	return filepath.Join(i.CacheDir, "java")
@@ -139,6 +186,10 @@ func (i *Instance) Platform() uint8 {
 	switch {
 	case i.Manifest.Requirements.FabricLoader != "":
 		return PlatformFabric
+	case i.Manifest.Requirements.QuiltLoader != "":
+		return PlatformQuilt
+	case i.Manifest.Requirements.NeoForgeLoader != "":
+		return PlatformNeoForge
 	case i.Manifest.Requirements.ForgeLoader != "":
 		return PlatformForge
 	default:
@@ -179,7 +230,7 @@ func New() *Instance {
 
 // NewFromDir tries to detect a instance in the given directory
 func NewFromDir(dir string) (*Instance, error) {
-	manifestToml, err := ioutil.ReadFile("./minepkg.toml")
+	manifestToml, err := ioutil.ReadFile(filepath.Join(dir, "minepkg.toml"))
 	if err != nil {
 		// TODO only for not found errors
 		return nil, ErrNoInstance
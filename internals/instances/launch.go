@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/minepkg/minepkg/internals/downloadmgr"
 	"github.com/minepkg/minepkg/internals/minecraft"
 	"github.com/minepkg/minepkg/pkg/manifest"
 	"github.com/pbnjay/memory"
@@ -29,8 +30,8 @@ import (
 )
 
 var (
-	// ErrLaunchNotImplemented is returned if attempting to start a non vanilla instance
-	ErrLaunchNotImplemented = errors.New("can only launch vanilla & fabric instances (for now)")
+	// ErrLaunchNotImplemented is returned if attempting to start an instance that can not be launched yet
+	ErrLaunchNotImplemented = errors.New("launching this instance is not supported (for now)")
 	// ErrNoCredentials is returned when an instance is launched without `MojangProfile` being set
 	ErrNoCredentials = errors.New("can not launch without mojang credentials")
 	// ErrNoPaidAccount is returned when an instance is launched without `MojangProfile` being set
@@ -453,6 +454,7 @@ func (i *Instance) launchManifestArgs(launchManifest *minecraft.LaunchManifest,
 	if opts.Server {
 		// we only use jvm args + main class for server
 		launchArgsTemplate = append(launchManifest.JVMArgs(), launchManifest.MainClass)
+		launchArgsTemplate = append(launchArgsTemplate, launchManifest.ServerArgs...)
 	} else {
 		// include all args for client
 		launchArgsTemplate = launchManifest.FullArgs()
@@ -500,9 +502,10 @@ func (i *Instance) readLaunchManifest() (*minecraft.LaunchManifest, error) {
 	switch i.Platform() {
 	case PlatformFabric:
 		return i.fetchFabricManifest(lockfile.Fabric)
-	case PlatformForge:
-		// TODO: forge
-		panic("Forge is not supported")
+	case PlatformQuilt:
+		return i.fetchQuiltManifest(lockfile.Quilt)
+	case PlatformNeoForge, PlatformForge:
+		return i.fetchInstallerManifest()
 	default:
 		return i.getVanillaManifest(lockfile.MinecraftVersion())
 	}
@@ -520,45 +523,63 @@ func (i *Instance) getVanillaManifest(v string) (*minecraft.LaunchManifest, erro
 }
 
 func (i *Instance) fetchFabricManifest(lock *manifest.FabricLock) (*minecraft.LaunchManifest, error) {
-	manifest := minecraft.LaunchManifest{}
-	loader := lock.FabricLoader
-	minecraft := lock.Minecraft
+	profileURL := fmt.Sprintf(
+		"https://meta.fabricmc.net/v2/versions/loader/%s/%s/profile/json",
+		url.QueryEscape(lock.Minecraft),
+		url.QueryEscape(lock.FabricLoader),
+	)
+	return i.fetchLoaderManifest("fabric", lock.Minecraft+"-fabric-"+lock.FabricLoader, profileURL)
+}
+
+func (i *Instance) fetchQuiltManifest(lock *manifest.QuiltLock) (*minecraft.LaunchManifest, error) {
+	profileURL := fmt.Sprintf(
+		"https://meta.quiltmc.org/v3/versions/loader/%s/%s/profile/json",
+		url.QueryEscape(lock.Minecraft),
+		url.QueryEscape(lock.QuiltLoader),
+	)
+	return i.fetchLoaderManifest("quilt", lock.Minecraft+"-quilt-"+lock.QuiltLoader, profileURL)
+}
 
-	version := minecraft + "-fabric-" + loader
-	dir := filepath.Join(i.VersionsDir(), minecraft+"-fabric-"+loader)
+// fetchLoaderManifest returns the cached launch manifest of a mod loader or downloads it from `profileURL`.
+// The manifest inherits from the vanilla manifest, see `GetLaunchManifest`
+func (i *Instance) fetchLoaderManifest(loaderName string, version string, profileURL string) (*minecraft.LaunchManifest, error) {
+	manifest := minecraft.LaunchManifest{}
+	dir := filepath.Join(i.VersionsDir(), version)
 	file := filepath.Join(dir, version+".json")
 
 	// cached
 	if rawMan, err := ioutil.ReadFile(file); err == nil {
 		err := json.Unmarshal(rawMan, &manifest)
 		if err == nil {
-			log.Println("Using cached fabric manifest", file)
+			log.Printf("Using cached %s manifest %s", loaderName, file)
 			return &manifest, nil
 		}
 		fmt.Printf("WARNING: Failed to parse cached manifest %s (this is a bug pls report)\n", file)
 		// corrupted manifest, try downloading
 	}
 
-	profileURL := fmt.Sprintf(
-		"https://meta.fabricmc.net/v2/versions/loader/%s/%s/profile/json",
-		url.QueryEscape(minecraft),
-		url.QueryEscape(loader),
-	)
-	log.Println("Fetching fabric manifest from", profileURL)
+	if i.Offline {
+		return nil, &ErrNotCached{Artifact: loaderName + " launch manifest " + version, Path: file}
+	}
+
+	log.Printf("Fetching %s manifest from %s", loaderName, profileURL)
 	res, err := http.Get(profileURL)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s meta API did respond with unexpected status %s", loaderName, res.Status)
+	}
 
 	buf, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, err
 	}
-	os.MkdirAll(dir, os.ModePerm)
-	if err != nil {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
 		return nil, err
 	}
-	ioutil.WriteFile(filepath.Join(dir, version+".json"), buf, 0666)
+	ioutil.WriteFile(file, buf, 0666)
 
 	if err = json.Unmarshal(buf, &manifest); err != nil {
 		return nil, err
@@ -572,7 +593,14 @@ func (i *Instance) CleanAfterExit() {
 }
 
 func (i *Instance) fetchVanillaManifest(version string) (*minecraft.LaunchManifest, error) {
-	mcVersions, err := GetMinecraftReleases(context.TODO())
+	if i.Offline {
+		return nil, &ErrNotCached{
+			Artifact: "launch manifest for Minecraft " + version,
+			Path:     filepath.Join(i.VersionsDir(), version, version+".json"),
+		}
+	}
+
+	mcVersions, err := GetMinecraftReleases(context.TODO(), i.MetaCache())
 	if err != nil {
 		return nil, err
 	}
@@ -588,12 +616,7 @@ func (i *Instance) fetchVanillaManifest(version string) (*minecraft.LaunchManife
 	}
 
 	manifest := minecraft.LaunchManifest{}
-	res, err := http.Get(manifestURL)
-	if err != nil {
-		return nil, err
-	}
-
-	buf, err := ioutil.ReadAll(res.Body)
+	buf, err := i.MetaCache().Get(context.TODO(), manifestURL)
 	if err != nil {
 		return nil, err
 	}
@@ -610,18 +633,9 @@ func (i *Instance) fetchVanillaManifest(version string) (*minecraft.LaunchManife
 	}
 
 	// TODO: this is a side effect. it should not be here
-	jarRes, err := http.Get(manifest.Downloads.Client.URL)
-	if err != nil {
-		return nil, err
-	}
-	jarDest, err := os.Create(filepath.Join(dir, version+".jar"))
-	if err != nil {
-		return nil, err
-	}
-	defer jarDest.Close()
-
-	// copy the jar
-	if _, err = io.Copy(jarDest, jarRes.Body); err != nil {
+	jar := downloadmgr.NewHTTPItem(manifest.Downloads.Client.URL, filepath.Join(dir, version+".jar"))
+	jar.Sha1 = manifest.Downloads.Client.Sha1
+	if err := jar.Download(context.TODO()); err != nil {
 		return nil, err
 	}
 
@@ -26,7 +26,7 @@ func (i *Instance) migrateManifest() error {
 		migrated = true
 	}
 	if m.Requirements.Forge != "" {
-		m.Requirements.FabricLoader = m.Requirements.Forge
+		m.Requirements.ForgeLoader = m.Requirements.Forge
 		m.Requirements.Forge = ""
 		migrated = true
 	}
@@ -40,7 +40,14 @@ func (i *Instance) migrateManifest() error {
 
 func (i *Instance) migrateLockfile() error {
 	if i.lockfileNeedsRenameMigration {
-		return os.Rename(i.legacyLockfilePath(), i.LockfilePath())
+		if err := os.Rename(i.legacyLockfilePath(), i.LockfilePath()); err != nil {
+			return err
+		}
+	}
+
+	// older lockfiles are upgraded in memory and written in the new format with the next save
+	if i.Lockfile != nil {
+		i.Lockfile.Upgrade()
 	}
 
 	return nil
@@ -0,0 +1,335 @@
+package instances
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/minepkg/minepkg/internals/fabric"
+	"github.com/minepkg/minepkg/internals/mcversion"
+	"github.com/minepkg/minepkg/internals/pkgcache"
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+// ModProblem is a `depends` or `breaks` entry in the fabric.mod.json of a linked mod that is not satisfied
+type ModProblem struct {
+	// Mod is the lock entry of the jar that contains the fabric.mod.json
+	Mod string
+	// ModID is the fabric mod id with the requirement. This can be a nested mod of `Mod`
+	ModID string
+	// Dependency is the required (or conflicting) mod id
+	Dependency string
+	// Predicates are the wanted versions of the dependency
+	Predicates []string
+	// Breaks is true if the dependency must not be installed in a matching version
+	Breaks bool
+	// Found is the version that is installed. It is empty if the dependency is missing
+	Found string
+	// Lock is the lock entry (or requirement) that provides the dependency
+	Lock string
+}
+
+// String returns a human readable description of the problem
+func (p *ModProblem) String() string {
+	wanted := strings.Join(p.Predicates, " || ")
+	switch {
+	case p.Breaks:
+		return fmt.Sprintf("%s is incompatible with %s %s (found %s from %q)", p.ModID, p.Dependency, wanted, p.Found, p.Lock)
+	case p.Found == "":
+		return fmt.Sprintf("%s requires %s %s, but it is missing", p.ModID, p.Dependency, wanted)
+	default:
+		return fmt.Sprintf("%s requires %s %s, but %s is locked (from %q)", p.ModID, p.Dependency, wanted, p.Found, p.Lock)
+	}
+}
+
+// Suggestion returns what should be changed to fix the problem
+func (p *ModProblem) Suggestion() string {
+	switch {
+	case p.Breaks:
+		return fmt.Sprintf("Remove or change %q, or change %q", p.Lock, p.Mod)
+	case p.Found == "":
+		return fmt.Sprintf("Add a dependency that provides %q, or remove %q", p.Dependency, p.Mod)
+	default:
+		return fmt.Sprintf("Change %q to a version matching %s, or change %q", p.Lock, strings.Join(p.Predicates, " || "), p.Mod)
+	}
+}
+
+// providedMod is a mod id that is available at launch
+type providedMod struct {
+	version string
+	// lock is the lock entry (or requirement) that provides this id
+	lock string
+}
+
+// CheckModRequirements reads the fabric.mod.json of every linked mod (including nested jars) and returns
+// all `depends` and `breaks` entries that are not satisfied by the locked Minecraft, loader and mod versions.
+// Only fabric & quilt instances are checked
+func (i *Instance) CheckModRequirements() ([]*ModProblem, error) {
+	platform := i.Platform()
+	if platform != PlatformFabric && platform != PlatformQuilt {
+		return nil, nil
+	}
+
+	provided := i.providedByRequirements()
+	// versions are not known or checked for these
+	unchecked := map[string]bool{"java": true}
+	if platform == PlatformQuilt {
+		// quilt provides fabricloader for compatibility
+		unchecked["fabricloader"] = true
+	}
+
+	jars, err := i.linkedModJars()
+	if err != nil {
+		return nil, err
+	}
+	mods := make(map[string][]*fabric.Jar, len(jars))
+	for name, path := range jars {
+		jar, err := readFabricJar(path)
+		if errors.Is(err, fabric.ErrNoFabricManifest) {
+			// not a fabric mod, nothing to check
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		mods[name] = flattenJar(jar)
+		for _, mod := range mods[name] {
+			for _, id := range append([]string{mod.Manifest.ID}, mod.Manifest.Provides...) {
+				provided[id] = append(provided[id], providedMod{version: mod.Manifest.Version, lock: name})
+			}
+		}
+	}
+
+	problems := make([]*ModProblem, 0)
+	for name, jars := range mods {
+		for _, jar := range jars {
+			for id, predicates := range jar.Manifest.Depends {
+				if unchecked[id] {
+					continue
+				}
+				candidates := provided[id]
+				if len(candidates) == 0 {
+					problems = append(problems, &ModProblem{Mod: name, ModID: jar.Manifest.ID, Dependency: id, Predicates: predicates})
+					continue
+				}
+				if match := matchingMod(candidates, predicates, true); match == nil {
+					problems = append(problems, &ModProblem{
+						Mod:        name,
+						ModID:      jar.Manifest.ID,
+						Dependency: id,
+						Predicates: predicates,
+						Found:      candidates[0].version,
+						Lock:       candidates[0].lock,
+					})
+				}
+			}
+			for id, predicates := range jar.Manifest.Breaks {
+				if unchecked[id] {
+					continue
+				}
+				if match := matchingMod(provided[id], predicates, false); match != nil {
+					problems = append(problems, &ModProblem{
+						Mod:        name,
+						ModID:      jar.Manifest.ID,
+						Dependency: id,
+						Predicates: predicates,
+						Breaks:     true,
+						Found:      match.version,
+						Lock:       match.lock,
+					})
+				}
+			}
+		}
+	}
+
+	sort.Slice(problems, func(a, b int) bool {
+		if problems[a].Mod != problems[b].Mod {
+			return problems[a].Mod < problems[b].Mod
+		}
+		return problems[a].Dependency < problems[b].Dependency
+	})
+	return problems, nil
+}
+
+// providedByRequirements returns Minecraft and the mod loader with their locked versions
+func (i *Instance) providedByRequirements() map[string][]providedMod {
+	provided := make(map[string][]providedMod)
+
+	// fabric uses its own format for snapshots, so only releases are checked
+	minecraft := i.Lockfile.MinecraftVersion()
+	if v, err := mcversion.Parse(minecraft); err != nil || !v.IsRelease() {
+		minecraft = "*"
+	}
+	provided["minecraft"] = []providedMod{{version: minecraft, lock: "requirements.minecraft"}}
+
+	if i.Lockfile.Fabric != nil {
+		provided["fabricloader"] = []providedMod{{version: i.Lockfile.Fabric.FabricLoader, lock: "requirements.fabricLoader"}}
+	}
+	if i.Lockfile.Quilt != nil {
+		provided["quilt_loader"] = []providedMod{{version: i.Lockfile.Quilt.QuiltLoader, lock: "requirements.quiltLoader"}}
+	}
+	return provided
+}
+
+// linkedModJars returns the cached jar of every mod that is linked into the mods folder by lock entry name
+func (i *Instance) linkedModJars() (map[string]string, error) {
+	jars := make(map[string]string)
+	for _, dep := range i.Lockfile.Dependencies {
+		if !dep.HasSource() || dep.Type == manifest.DependencyLockTypeModpack {
+			continue
+		}
+		path, err := i.PackageCache().Path(dep)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dep.Name, err)
+		}
+		if environment := i.dependencyEnvironment(dep, path); environment != "" && environment != i.side() {
+			continue
+		}
+		jars[dep.Name] = path
+	}
+	return jars, nil
+}
+
+// matchingMod returns the first candidate that satisfies the predicates.
+// Unknown versions ("*") only match if `unknownMatches` is true
+func matchingMod(candidates []providedMod, predicates []string, unknownMatches bool) *providedMod {
+	for n, candidate := range candidates {
+		if candidate.version == "*" {
+			if unknownMatches {
+				return &candidates[n]
+			}
+			continue
+		}
+		if fabric.VersionMatches(predicates, candidate.version) {
+			return &candidates[n]
+		}
+	}
+	return nil
+}
+
+func readFabricJar(path string) (*fabric.Jar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return fabric.ReadJar(f, stat.Size())
+}
+
+// flattenJar returns the jar and all nested jars
+func flattenJar(jar *fabric.Jar) []*fabric.Jar {
+	jars := []*fabric.Jar{jar}
+	for _, nested := range jar.Nested {
+		jars = append(jars, flattenJar(nested)...)
+	}
+	return jars
+}
+
+// ModConflicts returns the mod ids that are shipped by more than one linked dependency, either directly or
+// bundled via jar-in-jar in different versions. The mod index of the lockfile is used, jars of lock entries
+// without an index (written by older versions) are read from the package cache. Only fabric & quilt instances are checked
+func (i *Instance) ModConflicts() ([]*manifest.ModConflict, error) {
+	if !i.indexesMods() {
+		return nil, nil
+	}
+
+	jars, err := i.linkedModJars()
+	if err != nil {
+		return nil, err
+	}
+	mods := make(map[string][]*manifest.ProvidedMod, len(jars))
+	for name, path := range jars {
+		mods[name] = i.Lockfile.Dependencies[name].Mods
+		if len(mods[name]) != 0 {
+			continue
+		}
+		jar, err := readFabricJar(path)
+		if errors.Is(err, fabric.ErrNoFabricManifest) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		mods[name] = providedMods(jar, false)
+	}
+	return manifest.FindModConflicts(mods), nil
+}
+
+// IndexDependencies records the mod ids provided by every locked jar (including nested jars) in the lockfile.
+// The environment of jars without one from their provider is taken from their fabric.mod.json.
+// Jars that are not cached yet are downloaded first, so the lockfile does not depend on the state of the package cache.
+// Only fabric & quilt instances are indexed
+func (i *Instance) IndexDependencies(ctx context.Context) error {
+	if !i.indexesMods() {
+		return nil
+	}
+
+	missing := make([]*manifest.DependencyLock, 0)
+	for _, dep := range i.Lockfile.Dependencies {
+		if !isIndexed(dep) {
+			continue
+		}
+		_, err := i.PackageCache().Path(dep)
+		if errors.Is(err, pkgcache.ErrNotCached) {
+			missing = append(missing, dep)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", dep.Name, err)
+		}
+	}
+	if err := i.downloadDependencies(ctx, missing, i.DownloadProgress); err != nil {
+		return err
+	}
+
+	for _, dep := range i.Lockfile.Dependencies {
+		if !isIndexed(dep) {
+			continue
+		}
+		path, err := i.PackageCache().Path(dep)
+		if err != nil {
+			return fmt.Errorf("%s: %w", dep.Name, err)
+		}
+		dep.Mods = nil
+		jar, err := readFabricJar(path)
+		if err != nil {
+			// not a fabric mod or not readable, nothing to index
+			continue
+		}
+		dep.Mods = providedMods(jar, false)
+		if dep.Environment == "" {
+			dep.Environment = jar.Manifest.Side()
+		}
+	}
+	return nil
+}
+
+// indexesMods returns true if the jars of this instance are fabric mods
+func (i *Instance) indexesMods() bool {
+	platform := i.Platform()
+	return platform == PlatformFabric || platform == PlatformQuilt
+}
+
+// isIndexed returns true if the mods of the dependency are recorded in the lockfile
+func isIndexed(dep *manifest.DependencyLock) bool {
+	return dep.HasSource() && dep.Type != manifest.DependencyLockTypeModpack
+}
+
+// providedMods returns the mod ids of the jar (including `provides`) and of all nested jars
+func providedMods(jar *fabric.Jar, nested bool) []*manifest.ProvidedMod {
+	mods := make([]*manifest.ProvidedMod, 0, len(jar.Manifest.Provides)+1)
+	for _, id := range append([]string{jar.Manifest.ID}, jar.Manifest.Provides...) {
+		mods = append(mods, &manifest.ProvidedMod{ID: id, Version: jar.Manifest.Version, Nested: nested})
+	}
+	for _, child := range jar.Nested {
+		mods = append(mods, providedMods(child, true)...)
+	}
+	return mods
+}
@@ -0,0 +1,202 @@
+package instances
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+func testModJar(t *testing.T, files map[string][]byte) []byte {
+	buf := &bytes.Buffer{}
+	archive := zip.NewWriter(buf)
+	for name, content := range files {
+		file, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Write(content)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestInstance_CheckModRequirements(t *testing.T) {
+	dir := t.TempDir()
+	instance := &Instance{
+		Directory: filepath.Join(dir, "instance"),
+		CacheDir:  filepath.Join(dir, "cache"),
+		Manifest:  manifest.New(),
+		Lockfile:  manifest.NewLockfile(),
+	}
+	instance.Manifest.Requirements.FabricLoader = "*"
+	instance.Lockfile.Fabric = &manifest.FabricLock{Minecraft: "1.20.1", FabricLoader: "0.14.21"}
+
+	fabricAPIBase := testModJar(t, map[string][]byte{
+		"fabric.mod.json": []byte(`{"schemaVersion": 1, "id": "fabric-api-base", "version": "0.4.31"}`),
+	})
+	jars := map[string][]byte{
+		"fabric-api": testModJar(t, map[string][]byte{
+			"fabric.mod.json":                   []byte(`{"schemaVersion": 1, "id": "fabric-api", "version": "0.90.0+1.20.1", "provides": ["fabric"], "jars": [{"file": "META-INF/jars/fabric-api-base.jar"}]}`),
+			"META-INF/jars/fabric-api-base.jar": fabricAPIBase,
+		}),
+		"sodium-extra": testModJar(t, map[string][]byte{
+			"fabric.mod.json": []byte(`{
+				"schemaVersion": 1,
+				"id": "sodium-extra",
+				"version": "0.5.1",
+				"depends": {
+					"minecraft": "1.20.x",
+					"fabricloader": ">=0.15.0",
+					"fabric-api-base": "*",
+					"fabric": ["<0.80.0", ">=0.85.0"],
+					"sodium": ">=0.5.0",
+					"cloth-config": "*",
+					"java": ">=17"
+				},
+				"breaks": {"optifabric": "*", "sodium": "<0.4.0"}
+			}`),
+		}),
+		"sodium":        testModJar(t, map[string][]byte{"fabric.mod.json": []byte(`{"schemaVersion": 1, "id": "sodium", "version": "0.4.10+build.27"}`)}),
+		"optifabric":    testModJar(t, map[string][]byte{"fabric.mod.json": []byte(`{"schemaVersion": 1, "id": "optifabric", "version": "1.13.0"}`)}),
+		"plain-library": testModJar(t, map[string][]byte{"README.md": []byte("not a mod")}),
+	}
+	for name, content := range jars {
+		lock := &manifest.DependencyLock{Name: name, Version: "1.0.0", Type: manifest.DependencyLockTypeMod, URL: "https://example.com/" + name + ".jar"}
+		instance.Lockfile.AddDependency(lock)
+		src := filepath.Join(dir, name+".jar")
+		if err := os.WriteFile(src, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := instance.PackageCache().Store(lock, src); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	problems, err := instance.CheckModRequirements()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		dependency string
+		lock       string
+		breaks     bool
+	}{
+		{"cloth-config", "", false},
+		{"fabricloader", "requirements.fabricLoader", false},
+		{"optifabric", "optifabric", true},
+		{"sodium", "sodium", false},
+	}
+	if len(problems) != len(expected) {
+		for _, problem := range problems {
+			t.Log(problem)
+		}
+		t.Fatalf("expected %d problems, got %d", len(expected), len(problems))
+	}
+	for n, want := range expected {
+		got := problems[n]
+		if got.Mod != "sodium-extra" || got.Dependency != want.dependency || got.Lock != want.lock || got.Breaks != want.breaks {
+			t.Errorf("unexpected problem %d: %+v", n, got)
+		}
+	}
+
+	// snapshots are not checked
+	instance.Lockfile.Fabric.Minecraft = "23w31a"
+	problems, _ = instance.CheckModRequirements()
+	if len(problems) != len(expected) {
+		t.Errorf("expected the minecraft requirement to be skipped for snapshots, got %d problems", len(problems))
+	}
+}
+
+func TestInstance_ModConflicts(t *testing.T) {
+	dir := t.TempDir()
+	instance := &Instance{
+		Directory: filepath.Join(dir, "instance"),
+		CacheDir:  filepath.Join(dir, "cache"),
+		Manifest:  manifest.New(),
+		Lockfile:  manifest.NewLockfile(),
+	}
+	instance.Manifest.Requirements.FabricLoader = "*"
+	instance.Lockfile.Fabric = &manifest.FabricLock{Minecraft: "1.20.1", FabricLoader: "0.14.21"}
+
+	clothConfig := func(version string) []byte {
+		return testModJar(t, map[string][]byte{
+			"fabric.mod.json": []byte(`{"schemaVersion": 1, "id": "cloth-config", "version": "` + version + `"}`),
+		})
+	}
+	withClothConfig := func(id string, version string) []byte {
+		return testModJar(t, map[string][]byte{
+			"fabric.mod.json":                []byte(`{"schemaVersion": 1, "id": "` + id + `", "version": "1.0.0", "jars": [{"file": "META-INF/jars/cloth-config.jar"}]}`),
+			"META-INF/jars/cloth-config.jar": clothConfig(version),
+		})
+	}
+	jars := map[string][]byte{
+		"sodium":      testModJar(t, map[string][]byte{"fabric.mod.json": []byte(`{"schemaVersion": 1, "id": "sodium", "version": "0.5.8", "environment": "client"}`)}),
+		"sodium-fork": testModJar(t, map[string][]byte{"fabric.mod.json": []byte(`{"schemaVersion": 1, "id": "sodium-fork", "version": "0.5.3", "provides": ["sodium"]}`)}),
+		"mod-a":       withClothConfig("mod-a", "11.1.106"),
+		"mod-b":       withClothConfig("mod-b", "13.0.121"),
+	}
+	for name, content := range jars {
+		lock := &manifest.DependencyLock{Name: name, Version: "1.0.0", Type: manifest.DependencyLockTypeMod, URL: "https://example.com/" + name + ".jar"}
+		instance.Lockfile.AddDependency(lock)
+		src := filepath.Join(dir, name+".jar")
+		if err := os.WriteFile(src, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := instance.PackageCache().Store(lock, src); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the jar of this one is only available from the server
+	remote := testModJar(t, map[string][]byte{"fabric.mod.json": []byte(`{"schemaVersion": 1, "id": "remote", "version": "1.0.0", "provides": ["sodium"]}`)})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(remote)
+	}))
+	defer server.Close()
+	instance.Lockfile.AddDependency(&manifest.DependencyLock{Name: "remote", Version: "1.0.0", Type: manifest.DependencyLockTypeMod, URL: server.URL + "/remote.jar"})
+
+	if err := instance.IndexDependencies(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mods := instance.Lockfile.Dependencies["mod-a"].Mods
+	if len(mods) != 2 || mods[1].ID != "cloth-config" || mods[1].Version != "11.1.106" || !mods[1].Nested {
+		t.Fatalf("unexpected index %+v", mods)
+	}
+	if len(instance.Lockfile.Dependencies["remote"].Mods) != 2 {
+		t.Fatalf("expected the uncached jar to be downloaded and indexed, got %+v", instance.Lockfile.Dependencies["remote"].Mods)
+	}
+	if instance.Lockfile.Dependencies["sodium"].Environment != manifest.EnvironmentClient {
+		t.Errorf("expected the environment of the fabric.mod.json to be recorded, got %q", instance.Lockfile.Dependencies["sodium"].Environment)
+	}
+	if !strings.Contains(instance.Lockfile.String(), "cloth-config") {
+		t.Error("expected the index to be written to the lockfile")
+	}
+
+	// the index is used instead of the jar
+	instance.Lockfile.Dependencies["remote"].Mods = []*manifest.ProvidedMod{{ID: "remote", Version: "1.0.0"}}
+	conflicts, err := instance.ModConflicts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %d", len(conflicts))
+	}
+	if c := conflicts[0]; c.ID != "cloth-config" || c.IsDuplicate() || c.Keep().Dependency != "mod-b" || len(c.Drop()) != 0 {
+		t.Errorf("unexpected nested conflict %+v", c)
+	}
+	if c := conflicts[1]; c.ID != "sodium" || !c.IsDuplicate() || c.Keep().Dependency != "sodium" || len(c.Drop()) != 1 || c.Drop()[0] != "sodium-fork" {
+		t.Errorf("unexpected duplicate %+v", c)
+	}
+
+}
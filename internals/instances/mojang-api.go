@@ -2,9 +2,10 @@ package instances
 
 import (
 	"context"
-	"encoding/json"
-	"io/ioutil"
-	"net/http"
+	"time"
+
+	"github.com/minepkg/minepkg/internals/mcversion"
+	"github.com/minepkg/minepkg/internals/metacache"
 )
 
 const mcVersionsURL string = "https://launchermeta.mojang.com/mc/game/version_manifest.json"
@@ -39,21 +40,27 @@ type MinecraftReleaseResponse struct {
 }
 
 // GetMinecraftReleases returns all available Minecraft releases
-func GetMinecraftReleases(ctx context.Context) (*MinecraftReleaseResponse, error) {
-	req, _ := http.NewRequest("GET", mcVersionsURL, nil)
-
-	res, err := http.DefaultClient.Do(req.WithContext(ctx))
-	if err != nil {
-		return nil, err
-	}
-	buf, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
+func GetMinecraftReleases(ctx context.Context, cache *metacache.Cache) (*MinecraftReleaseResponse, error) {
 	parsed := MinecraftReleaseResponse{}
-	if err := json.Unmarshal(buf, &parsed); err != nil {
+	if err := cache.GetJSON(ctx, mcVersionsURL, &parsed); err != nil {
 		return nil, err
 	}
 
 	return &parsed, nil
 }
+
+// VersionList returns all versions ordered by their release time
+func (m *MinecraftReleaseResponse) VersionList() *mcversion.List {
+	versions := make([]*mcversion.Version, 0, len(m.Versions))
+	for _, release := range m.Versions {
+		v, err := mcversion.Parse(release.ID)
+		if err != nil {
+			// old versions (eg. b1.7.3) can only be ordered by their release time
+			v = &mcversion.Version{ID: release.ID}
+		}
+		v.Type = release.Type
+		v.ReleaseTime, _ = time.Parse(time.RFC3339, release.ReleaseTime)
+		versions = append(versions, v)
+	}
+	return mcversion.NewList(versions)
+}
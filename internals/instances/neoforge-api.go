@@ -0,0 +1,45 @@
+package instances
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// neoForgeMaven is the maven repository NeoForge is published to
+const neoForgeMaven = "https://maven.neoforged.net/releases/net/neoforged/neoforge"
+
+// getNeoForgeVersions returns all published NeoForge versions (oldest first)
+func getNeoForgeVersions(ctx context.Context) ([]string, error) {
+	return getMavenVersions(ctx, neoForgeMaven+"/maven-metadata.xml")
+}
+
+// neoForgeInstallerURL returns the download URL of the installer jar for a NeoForge version
+func neoForgeInstallerURL(version string) string {
+	return fmt.Sprintf("%s/%s/neoforge-%s-installer.jar", neoForgeMaven, version, version)
+}
+
+// neoForgeMinecraftVersion returns the Minecraft version a NeoForge version is built for.
+// NeoForge versions start with the Minecraft version without the leading "1." (eg. 21.1.77 is for 1.21.1
+// and 21.0.167 for 1.21). Versions for year based Minecraft versions keep all numbers (eg. 26.1.0.5 is for 26.1).
+// Returns "" if the version does not follow this scheme
+func neoForgeMinecraftVersion(version string) string {
+	release, _, _ := strings.Cut(version, "-")
+	parts := strings.Split(release, ".")
+	if len(parts) < 3 || parts[0] == "0" {
+		return ""
+	}
+
+	// year based Minecraft versions (26.1, 26.1.1 …)
+	if len(parts) == 4 {
+		if parts[2] == "0" {
+			return parts[0] + "." + parts[1]
+		}
+		return parts[0] + "." + parts[1] + "." + parts[2]
+	}
+
+	if parts[1] == "0" {
+		return "1." + parts[0]
+	}
+	return "1." + parts[0] + "." + parts[1]
+}
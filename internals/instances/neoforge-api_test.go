@@ -0,0 +1,21 @@
+package instances
+
+import "testing"
+
+func Test_neoForgeMinecraftVersion(t *testing.T) {
+	tests := map[string]string{
+		"20.2.86":                 "1.20.2",
+		"20.4.237":                "1.20.4",
+		"21.0.167":                "1.21",
+		"21.1.77":                 "1.21.1",
+		"21.0.0-beta":             "1.21",
+		"26.1.0.5-beta":           "26.1",
+		"26.1.1.2":                "26.1.1",
+		"0.25w14craftmine.3-beta": "",
+	}
+	for version, want := range tests {
+		if got := neoForgeMinecraftVersion(version); got != want {
+			t.Errorf("neoForgeMinecraftVersion(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package instances
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrOffline is returned if something needs network access while the instance is in offline mode
+var ErrOffline = errors.New("network access is disabled in offline mode")
+
+// ErrNotCached is returned in offline mode if a required file is not available locally
+type ErrNotCached struct {
+	// Artifact describes what is missing (eg. "launch manifest for 1.20.1")
+	Artifact string
+	// Path is where the artifact was expected
+	Path string
+}
+
+func (e *ErrNotCached) Error() string {
+	return fmt.Sprintf("offline mode: %s is not cached (expected at %s)", e.Artifact, e.Path)
+}
@@ -0,0 +1,51 @@
+package instances
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type quiltLoaderVersion struct {
+	Separator string `json:"separator"`
+	Build     int    `json:"build"`
+	Maven     string `json:"maven"`
+	Version   string `json:"version"`
+}
+
+type quiltGameVersion struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+}
+
+func getQuiltLoaderVersions(ctx context.Context) ([]quiltLoaderVersion, error) {
+	loaders := make([]quiltLoaderVersion, 0)
+	if err := quiltGetJSON(ctx, "https://meta.quiltmc.org/v3/versions/loader", &loaders); err != nil {
+		return nil, err
+	}
+	return loaders, nil
+}
+
+func getQuiltGameVersions(ctx context.Context) ([]quiltGameVersion, error) {
+	versions := make([]quiltGameVersion, 0)
+	if err := quiltGetJSON(ctx, "https://meta.quiltmc.org/v3/versions/game", &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func quiltGetJSON(ctx context.Context, url string, v interface{}) error {
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req.Header.Set("User-Agent", "minepkg (https://github.com/minepkg/minepkg)")
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return fmt.Errorf("quilt meta API did respond with unexpected status %s", res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
@@ -3,13 +3,23 @@ package instances
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/minepkg/minepkg/internals/commands"
+	"github.com/minepkg/minepkg/internals/mcversion"
 	"github.com/minepkg/minepkg/pkg/manifest"
 )
 
 var (
+	// ErrNoMinecraftVersion is returned if no Minecraft version matches the requirement
+	ErrNoMinecraftVersion = &commands.CliError{
+		Text: "Could not find a Minecraft version matching the requirement",
+		Suggestions: []string{
+			"Check the requirements.minecraft field in your minepkg.toml",
+			"Use \"snapshot:latest\" or an exact version (eg. 23w31a) to use snapshots",
+		},
+	}
 	// ErrNoFabricLoader is returned if the wanted fabric version was not found
 	ErrNoFabricLoader = &commands.CliError{
 		Text: "Could not find fabric loader for wanted Minecraft version",
@@ -28,11 +38,41 @@ var (
 			"Try again later",
 		},
 	}
+	// ErrNoQuiltLoader is returned if the wanted quilt loader version was not found
+	ErrNoQuiltLoader = &commands.CliError{
+		Text: "Could not find quilt loader for wanted Minecraft version",
+		Suggestions: []string{
+			"Check if quilt is compatible with the Minecraft version in your minepkg.toml",
+			"Check your requirements.quiltLoader field",
+			"Try again later",
+		},
+	}
+	// ErrNoNeoForgeLoader is returned if no NeoForge version matches the requirements
+	ErrNoNeoForgeLoader = &commands.CliError{
+		Text: "Could not find NeoForge for wanted Minecraft version",
+		Suggestions: []string{
+			"NeoForge only supports Minecraft 1.20.2 and newer",
+			"Check your requirements.neoforgeLoader field",
+			"Try again later",
+		},
+	}
+	// ErrNoForgeLoader is returned if no Forge version matches the requirements
+	ErrNoForgeLoader = &commands.CliError{
+		Text: "Could not find Forge for wanted Minecraft version",
+		Suggestions: []string{
+			"Check if Forge is available for the Minecraft version in your minepkg.toml",
+			"Check your requirements.forgeLoader field (a version range, \"latest\" or \"recommended\")",
+			"Try again later",
+		},
+	}
 )
 
-// UpdateLockfileRequirements updates the internal lockfile manifest with `VanillaLock`, `FabricLock` or `ForgeLock`
+// UpdateLockfileRequirements updates the internal lockfile manifest with `VanillaLock`, `FabricLock`, `QuiltLock`, `NeoForgeLock` or `ForgeLock`
 // containing the resolved requirements (semver requirement to actual version)
 func (i *Instance) UpdateLockfileRequirements(ctx context.Context) error {
+	if i.Offline {
+		return fmt.Errorf("can not resolve requirements: %w", ErrOffline)
+	}
 	if i.Lockfile == nil {
 		i.Lockfile = manifest.NewLockfile()
 	}
@@ -43,8 +83,24 @@ func (i *Instance) UpdateLockfileRequirements(ctx context.Context) error {
 			return err
 		}
 		i.Lockfile.Fabric = lock
+	case PlatformQuilt:
+		lock, err := i.resolveQuiltRequirement(ctx)
+		if err != nil {
+			return err
+		}
+		i.Lockfile.Quilt = lock
+	case PlatformNeoForge:
+		lock, err := i.resolveNeoForgeRequirement(ctx)
+		if err != nil {
+			return err
+		}
+		i.Lockfile.NeoForge = lock
 	case PlatformForge:
-		fmt.Println("forge is not supported for now")
+		lock, err := i.resolveForgeRequirement(ctx)
+		if err != nil {
+			return err
+		}
+		i.Lockfile.Forge = lock
 	case PlatformVanilla:
 		version, err := i.resolveVanillaRequirement(ctx)
 		if err != nil {
@@ -56,39 +112,46 @@ func (i *Instance) UpdateLockfileRequirements(ctx context.Context) error {
 }
 
 func (i *Instance) resolveVanillaRequirement(ctx context.Context) (*MinecraftRelease, error) {
-	constraint, _ := semver.NewConstraint(i.Manifest.Requirements.Minecraft)
-	res, err := GetMinecraftReleases(ctx)
+	constraint, err := mcversion.NewConstraint(i.Manifest.Requirements.Minecraft)
+	if err != nil {
+		return nil, err
+	}
+	res, err := GetMinecraftReleases(ctx, i.MetaCache())
 	if err != nil {
 		return nil, err
 	}
 
 	// find newest compatible version
+	newest := res.VersionList().Newest(constraint)
+	if newest == nil {
+		return nil, ErrNoMinecraftVersion
+	}
 	for _, v := range res.Versions {
-		// TODO: some versions contain spaces
-		semverVersion, err := semver.NewVersion(v.ID)
-
-		// skip non-parsable minecraft versions
-		if err != nil || constraint == nil {
-			// fallback to string compare
-			if v.ID == i.Manifest.Requirements.Minecraft {
-				return &v, nil
-			}
-			continue
-		}
-
-		if constraint.Check(semverVersion) {
+		if v.ID == newest.ID {
 			return &v, nil
 		}
 	}
 
-	return nil, nil
+	return nil, ErrNoMinecraftVersion
+}
+
+// minecraftRequirement returns the parsed Minecraft requirement and all known Minecraft versions
+func (i *Instance) minecraftRequirement(ctx context.Context) (*mcversion.Constraint, *mcversion.List, error) {
+	constraint, err := mcversion.NewConstraint(i.Manifest.Requirements.Minecraft)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := GetMinecraftReleases(ctx, i.MetaCache())
+	if err != nil {
+		return nil, nil, err
+	}
+	return constraint, res.VersionList(), nil
 }
 
 func (i *Instance) resolveFabricRequirement(ctx context.Context) (*manifest.FabricLock, error) {
-	reqMc := i.Manifest.Requirements.Minecraft
-	// latest is the same as '*' for this logic (it will return the latest version)
-	if reqMc == "latest" {
-		reqMc = "*"
+	MCconstraint, mcVersions, err := i.minecraftRequirement(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	reqFabric := i.Manifest.Requirements.FabricLoader
@@ -98,34 +161,32 @@ func (i *Instance) resolveFabricRequirement(ctx context.Context) (*manifest.Fabr
 	}
 
 	// TODO: check for invalid semver
-	MCconstraint, _ := semver.NewConstraint(reqMc)
 	FabricLoaderConstraint, _ := semver.NewConstraint(reqFabric)
-	// mcVersions, err := GetMinecraftReleases(ctx)
-
-	fabricMappings, err := getFabricMappingVersions(ctx)
+	fabricMappings, err := getFabricMappingVersions(ctx, i.MetaCache())
 	if err != nil {
 		return nil, err
 	}
-	fabricLoaders, err := getFabricLoaderVersions(ctx)
+	fabricLoaders, err := getFabricLoaderVersions(ctx, i.MetaCache())
 	if err != nil {
 		return nil, err
 	}
 
 	var foundMapping *fabricMappingVersion
+	var foundMc *mcversion.Version
 
 	// find newest compatible version
 	for _, v := range fabricMappings {
-		// TODO: some versions contain spaces
-		semverVersion, err := semver.NewVersion(v.GameVersion)
+		mcVersion, err := mcVersions.Get(v.GameVersion)
 
-		// skip unparsable minecraft versions
-		if err != nil {
+		// skip unknown minecraft versions
+		if err != nil || !MCconstraint.Check(mcVersion) {
 			continue
 		}
 
-		if MCconstraint.Check(semverVersion) {
+		// mappings of the same minecraft version are listed newest first
+		if foundMc == nil || mcVersion.GreaterThan(foundMc) {
 			foundMapping = &v
-			break
+			foundMc = mcVersion
 		}
 	}
 
@@ -160,3 +221,200 @@ func (i *Instance) resolveFabricRequirement(ctx context.Context) (*manifest.Fabr
 		FabricLoader: foundLoader.Version,
 	}, nil
 }
+
+func (i *Instance) resolveQuiltRequirement(ctx context.Context) (*manifest.QuiltLock, error) {
+	MCconstraint, mcVersions, err := i.minecraftRequirement(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reqQuilt := i.Manifest.Requirements.QuiltLoader
+	// latest is the same as '*' for this logic (it will return the latest version)
+	if reqQuilt == "latest" {
+		reqQuilt = "*"
+	}
+
+	QuiltLoaderConstraint, _ := semver.NewConstraint(reqQuilt)
+
+	gameVersions, err := getQuiltGameVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	quiltLoaders, err := getQuiltLoaderVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var foundGame *quiltGameVersion
+	var foundMc *mcversion.Version
+	// find newest compatible version
+	for _, v := range gameVersions {
+		mcVersion, err := mcVersions.Get(v.Version)
+
+		// skip unknown minecraft versions
+		if err != nil || !MCconstraint.Check(mcVersion) {
+			continue
+		}
+
+		if foundMc == nil || mcVersion.GreaterThan(foundMc) {
+			foundGame = &v
+			foundMc = mcVersion
+		}
+	}
+
+	if foundGame == nil {
+		return nil, ErrNoQuiltLoader
+	}
+
+	var foundLoader *quiltLoaderVersion
+	// find newest compatible version
+	for _, v := range quiltLoaders {
+		semverVersion, err := semver.NewVersion(v.Version)
+
+		// skip unparsable loader versions
+		if err != nil {
+			continue
+		}
+
+		if QuiltLoaderConstraint.Check(semverVersion) {
+			foundLoader = &v
+			break
+		}
+	}
+
+	if foundLoader == nil {
+		return nil, ErrNoQuiltLoader
+	}
+
+	return &manifest.QuiltLock{
+		Minecraft:   foundGame.Version,
+		QuiltLoader: foundLoader.Version,
+	}, nil
+}
+
+func (i *Instance) resolveNeoForgeRequirement(ctx context.Context) (*manifest.NeoForgeLock, error) {
+	MCconstraint, mcVersions, err := i.minecraftRequirement(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reqNeoForge := i.Manifest.Requirements.NeoForgeLoader
+	// latest is the same as '*' for this logic (it will return the latest version)
+	if reqNeoForge == "latest" {
+		reqNeoForge = "*"
+	}
+
+	NeoForgeConstraint, _ := semver.NewConstraint(reqNeoForge)
+
+	versions, err := getNeoForgeVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the maven metadata lists the oldest version first
+	var found *manifest.NeoForgeLock
+	var foundVersion *semver.Version
+	for _, v := range versions {
+		semverVersion, err := semver.NewVersion(v)
+		if err != nil {
+			continue
+		}
+		mcVersion, err := mcVersions.Get(neoForgeMinecraftVersion(v))
+		// skip versions that do not follow the usual scheme (april fools versions)
+		if err != nil {
+			continue
+		}
+
+		if !MCconstraint.Check(mcVersion) || !NeoForgeConstraint.Check(semverVersion) {
+			continue
+		}
+		if foundVersion == nil || semverVersion.GreaterThan(foundVersion) {
+			foundVersion = semverVersion
+			found = &manifest.NeoForgeLock{Minecraft: neoForgeMinecraftVersion(v), NeoForgeLoader: v}
+		}
+	}
+
+	if found == nil {
+		return nil, ErrNoNeoForgeLoader
+	}
+
+	return found, nil
+}
+
+func (i *Instance) resolveForgeRequirement(ctx context.Context) (*manifest.ForgeLock, error) {
+	MCconstraint, mcVersions, err := i.minecraftRequirement(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reqForge := i.Manifest.Requirements.ForgeLoader
+	// "latest" and "recommended" are looked up in the promotions, everything else is a version range
+	promoted := reqForge == "latest" || reqForge == "recommended"
+	ForgeConstraint, _ := semver.NewConstraint(reqForge)
+
+	versions, err := getForgeVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// all matching forge versions grouped by minecraft version
+	matching := make(map[string][]string)
+	var newestMc *mcversion.Version
+	var newestMcID string
+	for _, v := range versions {
+		mc, forgeVersion, ok := strings.Cut(v, "-")
+		if !ok {
+			continue
+		}
+		mcVersion, err := mcVersions.Get(mc)
+		// skip unknown minecraft versions (like 1.7.10_pre4)
+		if err != nil || !MCconstraint.Check(mcVersion) {
+			continue
+		}
+
+		if !promoted {
+			forgeSemver, err := looseSemver(forgeVersion)
+			switch {
+			case ForgeConstraint != nil && err == nil && ForgeConstraint.Check(forgeSemver):
+			case forgeVersion == reqForge:
+			default:
+				continue
+			}
+		}
+
+		matching[mc] = append(matching[mc], forgeVersion)
+		if newestMc == nil || mcVersion.GreaterThan(newestMc) {
+			newestMc = mcVersion
+			newestMcID = mc
+		}
+	}
+
+	if newestMc == nil {
+		return nil, ErrNoForgeLoader
+	}
+
+	candidates := matching[newestMcID]
+	if promoted {
+		promotions, err := getForgePromotions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// fall back to the latest version if nothing is recommended for this minecraft version
+		for _, promotion := range []string{newestMcID + "-" + reqForge, newestMcID + "-latest"} {
+			for _, candidate := range candidates {
+				if promotions[promotion] != "" && promotions[promotion] == candidate {
+					return &manifest.ForgeLock{Minecraft: newestMcID, ForgeLoader: candidate}, nil
+				}
+			}
+		}
+	}
+
+	newest := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if compareForgeVersions(candidate, newest) > 0 {
+			newest = candidate
+		}
+	}
+
+	return &manifest.ForgeLock{Minecraft: newestMcID, ForgeLoader: newest}, nil
+}
@@ -2,8 +2,10 @@ package instances
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/minepkg/minepkg/internals/mcversion"
 	"github.com/minepkg/minepkg/pkg/manifest"
 )
 
@@ -17,12 +19,14 @@ func requirementsInSync(lock *manifest.Lockfile, mani *manifest.Manifest) (bool,
 		return true, nil
 	}
 
-	mcVersionReq, err := semver.NewConstraint(mani.Requirements.Minecraft)
+	mcVersionReq, err := mcversion.NewConstraint(mani.Requirements.Minecraft)
 	if err != nil {
 		return false, err
 	}
 
-	if !mcVersionReq.Check(semver.MustParse(lock.MinecraftVersion())) {
+	// weekly snapshots can only be compared with the version manifest, so they are only in sync if required exactly
+	lockedMc, err := mcversion.Parse(lock.MinecraftVersion())
+	if err != nil || !mcVersionReq.Check(lockedMc) {
 		return true, nil
 	}
 
@@ -32,13 +36,20 @@ func requirementsInSync(lock *manifest.Lockfile, mani *manifest.Manifest) (bool,
 		return false, nil
 	}
 
+	// forge promotions can not be checked without fetching them
+	if mani.PlatformVersion() == "latest" || mani.PlatformVersion() == "recommended" {
+		return false, nil
+	}
+
 	platformVersionReq, err := semver.NewConstraint(mani.PlatformVersion())
 	if err != nil {
-		return false, err
+		// exact version (that is not semver)
+		return mani.PlatformVersion() != lock.PlatformLock().PlatformVersion(), nil
 	}
 
 	// check if the platform version is up to date (mod loader version)
-	if !platformVersionReq.Check(semver.MustParse(lock.PlatformLock().PlatformVersion())) {
+	lockedVersion, err := looseSemver(lock.PlatformLock().PlatformVersion())
+	if err != nil || !platformVersionReq.Check(lockedVersion) {
 		return true, nil
 	}
 
@@ -117,6 +128,21 @@ func dependenciesInSync(lock *manifest.Lockfile, mani *manifest.Manifest) (bool,
 	return false, nil
 }
 
+// unlockedDependencies returns the names of all dependencies that have no entry in the lockfile
+func unlockedDependencies(lock *manifest.Lockfile, mani *manifest.Manifest) []string {
+	unlocked := make([]string, 0)
+	for _, dep := range mani.InterpretedDependencies() {
+		if dep.Provider == "dummy" {
+			continue
+		}
+		if lock == nil || lock.Dependencies[dep.Name] == nil {
+			unlocked = append(unlocked, dep.Name)
+		}
+	}
+	sort.Strings(unlocked)
+	return unlocked
+}
+
 // DependenciesSynced returns true if the dependencies of this instance do not
 // match what is currently set in the lockfile. Dependencies should be updated with
 // "UpdateLockfileDependencies" in most cases if this is true
@@ -124,6 +150,12 @@ func (i *Instance) DependenciesSynced() (bool, error) {
 	return dependenciesInSync(i.Lockfile, i.Manifest)
 }
 
+// UnlockedDependencies returns the names of all dependencies that are missing in the lockfile.
+// In offline mode these can not be resolved
+func (i *Instance) UnlockedDependencies() []string {
+	return unlockedDependencies(i.Lockfile, i.Manifest)
+}
+
 // RequirementsSynced returns true if the requirements of this instance do not
 // match what is currently set in the lockfile. Requirements should be updated with
 // "UpdateLockfileRequirements" in most cases if this is true
@@ -70,6 +70,31 @@ func Test_areRequirementsInLockfileOutdated(t *testing.T) {
 		manifestOrBust("../../testdata/croptopia-manifest.toml"),
 	}
 
+	quiltManifest := func(loader string) *manifest.Manifest {
+		m := manifest.New()
+		m.Requirements.Minecraft = "~1.20.1"
+		m.Requirements.QuiltLoader = loader
+		return m
+	}
+	quiltLock := &manifest.Lockfile{Quilt: &manifest.QuiltLock{Minecraft: "1.20.1", QuiltLoader: "0.20.2"}}
+
+	forgeManifest := func(loader string) *manifest.Manifest {
+		m := manifest.New()
+		m.Requirements.Minecraft = "~1.12.2"
+		m.Requirements.ForgeLoader = loader
+		return m
+	}
+	forgeLock := &manifest.Lockfile{Forge: &manifest.ForgeLock{Minecraft: "1.12.2", ForgeLoader: "14.23.5.2859"}}
+
+	vanillaManifest := func(minecraft string) *manifest.Manifest {
+		m := manifest.New()
+		m.Requirements.Minecraft = minecraft
+		return m
+	}
+	vanillaLock := func(minecraft string) *manifest.Lockfile {
+		return &manifest.Lockfile{Vanilla: &manifest.VanillaLock{Minecraft: minecraft}}
+	}
+
 	type args struct {
 		lock *manifest.Lockfile
 		mani *manifest.Manifest
@@ -118,6 +143,78 @@ func Test_areRequirementsInLockfileOutdated(t *testing.T) {
 			want:    true,
 			wantErr: false,
 		},
+		{
+			name: "quilt lockfile matches",
+			args: args{
+				lock: quiltLock,
+				mani: quiltManifest(">=0.20.0"),
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "quilt loader requirement changed",
+			args: args{
+				lock: quiltLock,
+				mani: quiltManifest(">=0.21.0"),
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "forge lockfile matches (4 part version)",
+			args: args{
+				lock: forgeLock,
+				mani: forgeManifest("~14.23.5"),
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "forge exact version changed",
+			args: args{
+				lock: forgeLock,
+				mani: forgeManifest("14.23.5.2860"),
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "pre-release matches constraint",
+			args: args{
+				lock: vanillaLock("1.20.2-rc1"),
+				mani: vanillaManifest(">=1.20.2-pre1"),
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "snapshot required exactly",
+			args: args{
+				lock: vanillaLock("23w31a"),
+				mani: vanillaManifest("23w31a"),
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "snapshot but release required",
+			args: args{
+				lock: vanillaLock("23w31a"),
+				mani: vanillaManifest("~1.20.1"),
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "forge recommended",
+			args: args{
+				lock: forgeLock,
+				mani: forgeManifest("recommended"),
+			},
+			want:    false,
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -204,3 +301,19 @@ func Test_areDependenciesInLockfileOutdated(t *testing.T) {
 		})
 	}
 }
+
+func Test_unlockedDependencies(t *testing.T) {
+	mani := manifest.New()
+	mani.Dependencies = manifest.Dependencies{
+		"locked":   "^1.0.0",
+		"unlocked": "modrinth:unlocked@latest",
+		"disabled": "none",
+	}
+	lock := manifest.NewLockfile()
+	lock.AddDependency(&manifest.DependencyLock{Name: "locked", Version: "1.0.0"})
+
+	unlocked := unlockedDependencies(lock, mani)
+	if len(unlocked) != 1 || unlocked[0] != "unlocked" {
+		t.Errorf("expected only \"unlocked\" to be missing, got %v", unlocked)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/Masterminds/semver/v3"
 )
 
 func extractNative(jar string, target string) error {
@@ -82,3 +84,16 @@ func copyFileContents(src, dst string) (err error) {
 	err = out.Sync()
 	return
 }
+
+// looseSemver parses versions with more than three numbers (eg. 14.23.5.2859) by ignoring everything after the patch number
+func looseSemver(v string) (*semver.Version, error) {
+	if parsed, err := semver.NewVersion(v); err == nil {
+		return parsed, nil
+	}
+	release, _, _ := strings.Cut(v, "-")
+	parts := strings.Split(release, ".")
+	if len(parts) > 3 {
+		parts = parts[:3]
+	}
+	return semver.NewVersion(strings.Join(parts, "."))
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 	"runtime"
 	"time"
+
+	"github.com/minepkg/minepkg/internals/metacache"
 )
 
 const AdoptAPI = "https://api.adoptium.net/v3"
@@ -125,6 +127,14 @@ func (j *Factory) getAssets(ctx context.Context, opts *AdoptAssetRequest) ([]Ado
 		params.Encode(),
 	)
 
+	parsed := make([]AdoptAsset, 0, 1)
+	if j.metaCache != nil {
+		if err := j.adoptCache().GetJSON(ctx, p, &parsed); err != nil {
+			return nil, err
+		}
+		return parsed, nil
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "GET", p, nil)
 	if err != nil {
 		return nil, err
@@ -137,7 +147,6 @@ func (j *Factory) getAssets(ctx context.Context, opts *AdoptAssetRequest) ([]Ado
 		return nil, err
 	}
 
-	parsed := make([]AdoptAsset, 0, 1)
 	if err = json.NewDecoder(res.Body).Decode(&parsed); err != nil {
 		return nil, err
 	}
@@ -145,6 +154,19 @@ func (j *Factory) getAssets(ctx context.Context, opts *AdoptAssetRequest) ([]Ado
 	return parsed, nil
 }
 
+// adoptCache returns a copy of the meta cache that uses the http client of the factory
+// and sends the same headers as an uncached request
+func (j *Factory) adoptCache() *metacache.Cache {
+	cache := *j.metaCache
+	cache.HTTP = j.http
+	cache.Header = j.metaCache.Header.Clone()
+	if cache.Header == nil {
+		cache.Header = http.Header{}
+	}
+	cache.Header.Set("Accept", "application/json")
+	return &cache
+}
+
 func archMap(arch string) string {
 	theMap := map[string]string{
 		"amd64": "x64",
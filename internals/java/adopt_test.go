@@ -0,0 +1,52 @@
+package java
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/minepkg/minepkg/internals/metacache"
+)
+
+// countingTransport counts the requests that go through it
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestFactory_adoptCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/json" {
+			t.Errorf("expected Accept: application/json, got %q", r.Header.Get("Accept"))
+		}
+		if r.Header.Get("User-Agent") != "minepkg-test" {
+			t.Errorf("expected the User-Agent of the meta cache, got %q", r.Header.Get("User-Agent"))
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	cache := metacache.New(t.TempDir())
+	cache.Header = http.Header{"User-Agent": []string{"minepkg-test"}}
+
+	factory := NewFactory(t.TempDir())
+	factory.SetHTTPClient(&http.Client{Transport: transport})
+	factory.SetMetaCache(cache)
+
+	var parsed []AdoptAsset
+	if err := factory.adoptCache().GetJSON(context.Background(), server.URL, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if transport.requests != 1 {
+		t.Errorf("expected the request to use the http client of the factory, it got %d requests", transport.requests)
+	}
+	if cache.Header.Get("Accept") != "" {
+		t.Error("expected the shared meta cache headers to be unchanged")
+	}
+}
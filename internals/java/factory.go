@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+
+	"github.com/minepkg/minepkg/internals/metacache"
 )
 
 var (
@@ -15,17 +17,23 @@ var (
 	ErrInvalidFeatureVersion    = errors.New("invalid feature version. must be a number between 1 and 65535")
 	ErrInvalidImageType         = errors.New("invalid image type. must be either jdk, jre, testimage or debugimage")
 	ErrInvalidJvmImplementation = errors.New("invalid jvm implementation. must be hotspot or openj9")
+	// ErrNotInstalled is returned in offline mode if the wanted java version is not installed yet
+	ErrNotInstalled = errors.New("java version is not installed")
 )
 
 type Factory struct {
-	baseDir string
-	http    *http.Client
+	// Offline prevents looking up java versions that are not installed yet
+	Offline bool
+
+	baseDir   string
+	http      *http.Client
+	metaCache *metacache.Cache
 }
 
 func NewFactory(baseDir string) *Factory {
 	return &Factory{
-		baseDir,
-		http.DefaultClient,
+		baseDir: baseDir,
+		http:    http.DefaultClient,
 	}
 }
 
@@ -34,6 +42,11 @@ func (j *Factory) SetHTTPClient(c *http.Client) {
 	j.http = c
 }
 
+// SetMetaCache caches the responses of the Adoptium API in the given cache
+func (j *Factory) SetMetaCache(c *metacache.Cache) {
+	j.metaCache = c
+}
+
 func (j *Factory) Version(ctx context.Context, wantedVersion string) (*Java, error) {
 	wanted, err := newWantedVersion(wantedVersion)
 	if err != nil {
@@ -67,6 +80,10 @@ func (j *Factory) Version(ctx context.Context, wantedVersion string) (*Java, err
 		}
 	}
 
+	if j.Offline {
+		return nil, fmt.Errorf("%w: %s (expected at %s)", ErrNotInstalled, fullName, p)
+	}
+
 	// no cached version, downloading
 	assets, err := j.getAssets(ctx, &wanted.AdoptAssetRequest)
 	if err != nil {
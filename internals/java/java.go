@@ -3,13 +3,12 @@ package java
 import (
 	"context"
 	"encoding/json"
-	"io"
-	"io/ioutil"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+
+	"github.com/minepkg/minepkg/internals/downloadmgr"
 )
 
 type Java struct {
@@ -50,6 +49,7 @@ func (j *Java) Update(ctx context.Context) error {
 		return err
 	}
 	defer os.Remove(archive.Name()) // remove temporary download
+	archive.Close()
 
 	// extract the whole archive
 	// validation of the extraction happens inside extractArchive (zip slip protection)
@@ -87,28 +87,23 @@ func (j *Java) Update(ctx context.Context) error {
 
 func (j *Java) download(ctx context.Context) (*os.File, error) {
 	url := j.downloadURL()
-	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
 
 	ext := ".tar.gz"
 	if !strings.HasSuffix(url, ".tar.gz") {
 		ext = filepath.Ext(url)
 	}
-	archive, err := ioutil.TempFile("", "minepkg-java.*"+ext)
-	if err != nil {
-		return nil, err
-	}
-	defer archive.Close()
 
-	_, err = io.Copy(archive, res.Body)
-	if err != nil {
+	// a fixed path next to the java dir, so interrupted downloads can be resumed
+	item := downloadmgr.NewHTTPItem(url, j.dir+".download"+ext)
+	item.Sha256 = j.asset.Binaries[0].Package.Checksum
+
+	mgr := downloadmgr.New()
+	mgr.Add(item)
+	if err := mgr.Start(ctx); err != nil {
 		return nil, err
 	}
 
-	return archive, nil
+	return os.Open(item.Target)
 }
 
 func (j *Java) downloadURL() string {
@@ -3,6 +3,7 @@ package launcher
 import (
 	"fmt"
 
+	"github.com/minepkg/minepkg/pkg/manifest"
 	"github.com/spf13/cobra"
 )
 
@@ -10,6 +11,8 @@ import (
 type OverwriteFlags struct {
 	McVersion        string
 	FabricVersion    string
+	QuiltVersion     string
+	NeoForgeVersion  string
 	ForgeVersion     string
 	MinepkgCompanion string
 	Java             string
@@ -20,6 +23,9 @@ func CmdOverwriteFlags(cmd *cobra.Command) *OverwriteFlags {
 	flags := OverwriteFlags{}
 	cmd.Flags().StringVarP(&flags.McVersion, "minecraft", "m", "", "Overwrite the required Minecraft version")
 	cmd.Flags().StringVar(&flags.FabricVersion, "fabricLoader", "", "Overwrite the required fabricLoader version")
+	cmd.Flags().StringVar(&flags.QuiltVersion, "quiltLoader", "", "Overwrite the required quiltLoader version")
+	cmd.Flags().StringVar(&flags.NeoForgeVersion, "neoforgeLoader", "", "Overwrite the required neoforgeLoader version")
+	cmd.Flags().StringVar(&flags.ForgeVersion, "forgeLoader", "", "Overwrite the required forgeLoader version")
 	cmd.Flags().StringVar(&flags.MinepkgCompanion, "minepkgCompanion", "", "Overwrite the required minepkg companion version (can also be \"none\")")
 	cmd.Flags().IntVar(&flags.Ram, "ram", 0, "Overwrite the amount of RAM in MiB to use")
 	cmd.Flags().StringVar(&flags.Java, "java", "", "Overwrite the Java runtime. Examples: 16-jre, 8-jre-openj9, system")
@@ -27,11 +33,34 @@ func CmdOverwriteFlags(cmd *cobra.Command) *OverwriteFlags {
 	return &flags
 }
 
+// Platform returns the platform packages are queried for. This is fabric unless another loader was set
+func (o *OverwriteFlags) Platform() string {
+	switch {
+	case o.NeoForgeVersion != "":
+		return manifest.PlatformNeoForge
+	case o.ForgeVersion != "":
+		return manifest.PlatformForge
+	case o.QuiltVersion != "":
+		return manifest.PlatformQuilt
+	default:
+		return manifest.PlatformFabric
+	}
+}
+
 func (l *Launcher) ApplyOverWrites(o *OverwriteFlags) {
 	instance := l.Instance
 	if o.FabricVersion != "" {
 		instance.Manifest.Requirements.FabricLoader = o.FabricVersion
 	}
+	if o.QuiltVersion != "" {
+		instance.Manifest.Requirements.QuiltLoader = o.QuiltVersion
+	}
+	if o.NeoForgeVersion != "" {
+		instance.Manifest.Requirements.NeoForgeLoader = o.NeoForgeVersion
+	}
+	if o.ForgeVersion != "" {
+		instance.Manifest.Requirements.ForgeLoader = o.ForgeVersion
+	}
 	if o.McVersion != "" {
 		fmt.Println("Minecraft version overwritten to version: " + o.McVersion)
 		instance.Manifest.Requirements.Minecraft = o.McVersion
@@ -52,6 +52,15 @@ func (c *Launcher) HandleCrash() error {
 			c.Instance.Lockfile.Fabric.Mapping,
 		)
 	}
+	if platform == "quilt" {
+		fmt.Printf("  quilt: %s (loader)\n", c.Instance.Lockfile.Quilt.QuiltLoader)
+	}
+	if platform == "neoforge" {
+		fmt.Printf("  neoforge: %s\n", c.Instance.Lockfile.NeoForge.NeoForgeLoader)
+	}
+	if platform == "forge" {
+		fmt.Printf("  forge: %s\n", c.Instance.Lockfile.Forge.ForgeLoader)
+	}
 	fmt.Printf("  exit code: %d\n", c.Cmd.ProcessState.ExitCode())
 
 	fmt.Println("\nSubmitting crash report to minepkg.io …")
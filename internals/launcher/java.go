@@ -10,8 +10,17 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/minepkg/minepkg/internals/java"
+	"github.com/minepkg/minepkg/internals/mcversion"
 )
 
+// javaBin returns the java executable that is used to launch Minecraft
+func (l *Launcher) javaBin() string {
+	if l.UseSystemJava {
+		return "java"
+	}
+	return l.java.Bin()
+}
+
 func (l *Launcher) Java(ctx context.Context) (*java.Java, error) {
 	if l.java != nil {
 		return l.java, nil
@@ -39,9 +48,9 @@ func (l *Launcher) Java(ctx context.Context) (*java.Java, error) {
 	if l.LaunchManifest.JavaVersion != nil && l.LaunchManifest.JavaVersion.MajorVersion != 0 {
 		v = fmt.Sprintf("%d", l.LaunchManifest.JavaVersion.MajorVersion)
 	} else {
-		// or fallback to v16 for 1.17
-		mcSemver := semver.MustParse(l.Instance.Lockfile.MinecraftVersion())
-		if mcSemver.Equal(semver.MustParse("1.17.0")) || mcSemver.GreaterThan(semver.MustParse("1.17.0")) {
+		// or fallback to v16 for 1.17 (weekly snapshots can not be ordered here, they are usually new)
+		mcVersion, err := mcversion.Parse(l.Instance.Lockfile.MinecraftVersion())
+		if err == nil && (mcVersion.Semver() == nil || !mcVersion.Semver().LessThan(semver.MustParse("1.17.0-0"))) {
 			v = "16"
 		}
 	}
@@ -73,5 +82,7 @@ func (l Launcher) javaFactory() (*java.Factory, error) {
 		return nil, err
 	}
 	l.javaFactoryInstance = java.NewFactory(filepath.Join(userCache, "minepkg", "java"))
+	l.javaFactoryInstance.Offline = l.Instance.Offline
+	l.javaFactoryInstance.SetMetaCache(l.Instance.MetaCache())
 	return l.javaFactoryInstance, nil
 }
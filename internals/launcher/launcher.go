@@ -43,6 +43,9 @@ type Launcher struct {
 	// JavaVersion is the version to use
 	JavaVersion string
 
+	// SkipModChecks launches even if the fabric.mod.json requirements of mods are not met or mod ids are duplicated
+	SkipModChecks bool
+
 	javaFactoryInstance *java.Factory
 	java                *java.Java
 	introPrinted        bool
@@ -0,0 +1,116 @@
+package launcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jwalton/gchalk"
+	"github.com/minepkg/minepkg/internals/commands"
+	"github.com/minepkg/minepkg/internals/instances"
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+// checkModRequirements reports mods with unmet fabric.mod.json requirements.
+// The game would not start with them, so this fails early unless `SkipModChecks` is set
+func (l *Launcher) checkModRequirements() error {
+	problems, err := l.Instance.CheckModRequirements()
+	if err != nil {
+		// the mod loader checks this again while launching
+		log.Println("Could not check mod requirements:", err)
+		return nil
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	fmt.Println(gchalk.Yellow(fmt.Sprintf("\n%d mod requirements are not met:", len(problems))))
+	for _, problem := range problems {
+		fmt.Printf("  %s %s\n", gchalk.Bold(problem.Mod+":"), problem.String())
+		fmt.Println(gchalk.Gray("    → " + problem.Suggestion()))
+	}
+	fmt.Println()
+
+	if l.SkipModChecks {
+		return nil
+	}
+	return &commands.CliError{
+		Text: "mod requirements are not met",
+		Suggestions: []string{
+			fmt.Sprintf("Change the listed entries in your minepkg.toml and run %s", gchalk.Bold("minepkg update")),
+			fmt.Sprintf("Launch with %s to start anyway", gchalk.Bold("--skip-mod-checks")),
+		},
+	}
+}
+
+// checkModConflicts reports mods that are shipped by more than one dependency.
+// The game would not start with duplicate mod ids, so this fails early unless `SkipModChecks` is set.
+// Different versions of nested mods are only a warning because the loader picks the newest one
+func (l *Launcher) checkModConflicts() error {
+	conflicts, err := l.Instance.ModConflicts()
+	if err != nil {
+		log.Println("Could not check for duplicate mods:", err)
+		return nil
+	}
+
+	duplicates := PrintModConflicts(l.Instance, conflicts)
+	if duplicates == 0 || l.SkipModChecks {
+		return nil
+	}
+	return &commands.CliError{
+		Text: fmt.Sprintf("%d mods are installed more than once", duplicates),
+		Suggestions: []string{
+			"Drop the listed dependencies from your minepkg.toml",
+			fmt.Sprintf("Launch with %s to start anyway", gchalk.Bold("--skip-mod-checks")),
+		},
+	}
+}
+
+// PrintModConflicts prints the conflicts with a suggestion which dependency to drop
+// and returns the number of duplicate mods (the ones that keep the game from starting)
+func PrintModConflicts(instance *instances.Instance, conflicts []*manifest.ModConflict) int {
+	if len(conflicts) == 0 {
+		return 0
+	}
+
+	duplicates := 0
+	fmt.Println(gchalk.Yellow(fmt.Sprintf("\n%d mods are shipped by more than one dependency:", len(conflicts))))
+	for _, conflict := range conflicts {
+		providers := make([]string, len(conflict.Providers))
+		for n, provider := range conflict.Providers {
+			providers[n] = fmt.Sprintf("%s@%s from %q", conflict.ID, provider.Version, provider.Dependency)
+			if provider.Nested {
+				providers[n] += " (nested)"
+			}
+		}
+		label := "different versions"
+		if conflict.IsDuplicate() {
+			label = "duplicate"
+			duplicates++
+		}
+		fmt.Printf("  %s %s: %s\n", gchalk.Bold(conflict.ID+":"), label, strings.Join(providers, ", "))
+
+		keep := conflict.Keep()
+		drop := conflict.Drop()
+		if len(drop) == 0 {
+			fmt.Println(gchalk.Gray(fmt.Sprintf("    → The loader uses %s from %q", keep.Version, keep.Dependency)))
+			continue
+		}
+		for _, name := range drop {
+			fmt.Println(gchalk.Gray("    → " + dropSuggestion(instance, name, keep)))
+		}
+	}
+	fmt.Println()
+	return duplicates
+}
+
+// dropSuggestion explains how to get rid of the given dependency
+func dropSuggestion(instance *instances.Instance, name string, keep *manifest.ModProvider) string {
+	_, direct := instance.Manifest.Dependencies[name]
+	_, directDev := instance.Manifest.Dev.Dependencies[name]
+	if direct || directDev {
+		return fmt.Sprintf("Drop %q (run %s) and keep %q", name, gchalk.Bold("minepkg remove "+name), keep.Dependency)
+	}
+	dependents := instance.Lockfile.DependentsOf(name)
+	return fmt.Sprintf("Drop %q and keep %q. It is required by %s", name, keep.Dependency, strings.Join(dependents, ", "))
+}
@@ -13,8 +13,10 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jwalton/gchalk"
 	"github.com/minepkg/minepkg/internals/downloadmgr"
+	"github.com/minepkg/minepkg/internals/instances"
 	"github.com/minepkg/minepkg/internals/minecraft"
 	"github.com/minepkg/minepkg/internals/patch"
+	"github.com/minepkg/minepkg/pkg/manifest"
 	"github.com/spf13/viper"
 )
 
@@ -24,6 +26,11 @@ func (l *Launcher) Prepare() error {
 	instance := l.Instance
 	ctx := context.Background()
 
+	if l.ServerMode {
+		// client only mods are not linked
+		instance.Environment = manifest.EnvironmentServer
+	}
+
 	l.printIntro()
 	l.introPrinted = true
 
@@ -33,16 +40,21 @@ func (l *Launcher) Prepare() error {
 		return fmt.Errorf("failed to update requirements: %w", err)
 	}
 
-	// download minecraft (assets, libraries, main jar etc) if needed
 	// needs to happen before javaUpdate because launch manifest
 	// might contain wanted java version
-	if err := l.PrepareMinecraft(ctx); err != nil {
-		return fmt.Errorf("failed to download minecraft: %w", err)
+	if err := l.PrepareLaunchManifest(ctx); err != nil {
+		return fmt.Errorf("failed to prepare minecraft: %w", err)
 	}
 
 	// update java in the background if needed
+	// it shares the download slots with the minecraft downloads below
 	javaUpdate := l.PrepareJavaBg(ctx)
 
+	// download minecraft (assets, libraries, main jar etc) if needed
+	if err := l.PrepareMinecraft(ctx); err != nil {
+		return fmt.Errorf("failed to download minecraft: %w", err)
+	}
+
 	// update dependencies
 	log.Println("Preparing dependencies")
 	if err := l.PrepareDependencies(ctx, outdatedReqs); err != nil {
@@ -59,6 +71,14 @@ func (l *Launcher) Prepare() error {
 		return fmt.Errorf("failed to link dependencies: %w", err)
 	}
 
+	log.Println("Checking mod requirements")
+	if err := l.checkModRequirements(); err != nil {
+		return err
+	}
+	if err := l.checkModConflicts(); err != nil {
+		return err
+	}
+
 	log.Println("Copying overwrites")
 	if err := instance.CopyOverwrites(); err != nil {
 		return fmt.Errorf("failed to copy overwrites: %w", err)
@@ -77,6 +97,13 @@ func (l *Launcher) Prepare() error {
 		return err
 	}
 
+	// some mod loaders (Forge & NeoForge) patch the Minecraft jar with java before the first launch
+	launchManifest, err := l.Instance.InstallModLoader(ctx, l.LaunchManifest, l.javaBin(), l.ServerMode)
+	if err != nil {
+		return fmt.Errorf("failed to install mod loader: %w", err)
+	}
+	l.LaunchManifest = launchManifest
+
 	l.printOutro()
 
 	return nil
@@ -93,6 +120,10 @@ func (l Launcher) PrepareRequirements() (bool, error) {
 	}
 
 	fmt.Print(pipeText.Render(gchalk.BgGray("Requirements")))
+	if instance.Offline && (l.ForceUpdate || outdatedReqs) {
+		fmt.Println()
+		return false, fmt.Errorf("requirements in the lockfile are missing or do not match the manifest: %w", instances.ErrOffline)
+	}
 	if l.ForceUpdate || outdatedReqs {
 		fmt.Print(gchalk.Gray("(updating)"))
 		err := instance.UpdateLockfileRequirements(context.TODO())
@@ -112,6 +143,15 @@ func (l Launcher) PrepareRequirements() (bool, error) {
 			instance.Lockfile.Fabric.Mapping,
 		)
 	}
+	if instance.Manifest.PlatformString() == "quilt" {
+		fmt.Printf("│ Quilt: %s (loader)\n", instance.Lockfile.Quilt.QuiltLoader)
+	}
+	if instance.Manifest.PlatformString() == "neoforge" {
+		fmt.Printf("│ NeoForge: %s\n", instance.Lockfile.NeoForge.NeoForgeLoader)
+	}
+	if instance.Manifest.PlatformString() == "forge" {
+		fmt.Printf("│ Forge: %s\n", instance.Lockfile.Forge.ForgeLoader)
+	}
 	fmt.Println("│")
 	return outdatedReqs, nil
 }
@@ -156,6 +196,18 @@ func (l *Launcher) PrepareDependencies(ctx context.Context, force bool) error {
 
 	// also update dependencies when requirements are outdated
 	fmt.Print(pipeText.Render(gchalk.BgGray("Dependencies")))
+	if instance.Offline {
+		if force || l.ForceUpdate {
+			fmt.Println()
+			return fmt.Errorf("can not update dependencies: %w", instances.ErrOffline)
+		}
+		// without network we can only use what is locked, even if it might be outdated
+		if unlocked := instance.UnlockedDependencies(); len(unlocked) != 0 {
+			fmt.Println()
+			return fmt.Errorf("%s missing in the lockfile: %w", strings.Join(unlocked, ", "), instances.ErrOffline)
+		}
+		outdatedDependencies = false
+	}
 	if force || l.ForceUpdate || outdatedDependencies {
 		fmt.Print(gchalk.Gray("(updating)\n"))
 		if err := l.fetchDependencies(ctx); err != nil {
@@ -172,9 +224,9 @@ func (l *Launcher) PrepareDependencies(ctx context.Context, force bool) error {
 	return nil
 }
 
-func (l *Launcher) PrepareMinecraft(ctx context.Context) error {
+// PrepareLaunchManifest applies all patches and sets the LaunchManifest
+func (l *Launcher) PrepareLaunchManifest(ctx context.Context) error {
 	instance := l.Instance
-	mgr := downloadmgr.New()
 
 	fmt.Println(pipeText.Render(gchalk.Gray("Preparing Minecraft")))
 
@@ -195,6 +247,9 @@ func (l *Launcher) PrepareMinecraft(ctx context.Context) error {
 	lgwlSetting := viper.GetString("LWJGL")
 	if (runtime.GOARCH != "amd64" && lgwlSetting != "inherit") || lgwlSetting == "patched" {
 		log.Println("Patching in custom compatible lwjgl version")
+		if instance.Offline {
+			return fmt.Errorf("can not fetch lwjgl patch: %w", instances.ErrOffline)
+		}
 
 		// TODO: cache
 		lwjglPatch, err := patch.FetchPatchFromURL(
@@ -217,11 +272,34 @@ func (l *Launcher) PrepareMinecraft(ctx context.Context) error {
 		return fmt.Errorf("failed to get launch manifest: %w", err)
 	}
 
+	l.LaunchManifest = launchManifest
+	return nil
+}
+
+// PrepareMinecraft downloads the Minecraft jar, assets & libraries if needed.
+// Calls PrepareLaunchManifest first if the LaunchManifest is not set yet
+func (l *Launcher) PrepareMinecraft(ctx context.Context) error {
+	instance := l.Instance
+	mgr := downloadmgr.New()
+
+	if l.LaunchManifest == nil {
+		if err := l.PrepareLaunchManifest(ctx); err != nil {
+			return err
+		}
+	}
+	launchManifest := l.LaunchManifest
+
 	// check for JAR
 	// TODO move more logic to internals
 	mainJar := filepath.Join(l.Instance.VersionsDir(), launchManifest.MinecraftVersion(), launchManifest.JarName())
+	// everything that is not cached yet. downloaded unless we are offline
+	missing := make([]*instances.ErrNotCached, 0)
+
 	if _, err := os.Stat(mainJar); os.IsNotExist(err) {
-		mgr.Add(downloadmgr.NewHTTPItem(launchManifest.Downloads.Client.URL, mainJar))
+		item := downloadmgr.NewHTTPItem(launchManifest.Downloads.Client.URL, mainJar)
+		item.Sha1 = launchManifest.Downloads.Client.Sha1
+		mgr.Add(item)
+		missing = append(missing, &instances.ErrNotCached{Artifact: "Minecraft jar", Path: mainJar})
 	}
 
 	if !l.ServerMode {
@@ -236,7 +314,11 @@ func (l *Launcher) PrepareMinecraft(ctx context.Context) error {
 
 		for _, asset := range missingAssets {
 			target := filepath.Join(instance.CacheDir, "assets/objects", asset.UnixPath())
-			mgr.Add(downloadmgr.NewHTTPItem(asset.DownloadURL(), target))
+			item := downloadmgr.NewHTTPItem(asset.DownloadURL(), target)
+			// assets are named after their sha1 hash
+			item.Sha1 = asset.Hash
+			mgr.Add(item)
+			missing = append(missing, &instances.ErrNotCached{Artifact: "asset " + asset.Hash, Path: target})
 		}
 	}
 
@@ -252,7 +334,17 @@ func (l *Launcher) PrepareMinecraft(ctx context.Context) error {
 
 	for _, lib := range missingLibs {
 		target := filepath.Join(instance.CacheDir, "libraries", lib.Filepath())
-		mgr.Add(downloadmgr.NewHTTPItem(lib.DownloadURL(), target))
+		item := downloadmgr.NewHTTPItem(lib.DownloadURL(), target)
+		item.Sha1 = lib.DownloadSha1()
+		mgr.Add(item)
+		missing = append(missing, &instances.ErrNotCached{Artifact: "library " + lib.Name, Path: target})
+	}
+
+	if instance.Offline && len(missing) != 0 {
+		for _, m := range missing {
+			fmt.Println(pipeText.Render(gchalk.Red("missing ") + m.Artifact + gchalk.Gray(" "+m.Path)))
+		}
+		return missing[0]
 	}
 
 	log.Println("Starting downloads")
@@ -262,7 +354,6 @@ func (l *Launcher) PrepareMinecraft(ctx context.Context) error {
 
 	fmt.Println(pipeText.Render(""))
 
-	l.LaunchManifest = launchManifest
 	return nil
 }
 
@@ -345,7 +436,6 @@ func (c *Launcher) fetchDependencies(ctx context.Context) error {
 		lock := resolved.Lock()
 		// TODO: allow them to set the name
 		lock.Name = resolved.Key
-		instance.Lockfile.AddDependency(lock)
 		fmt.Println(dependencyLine(lock))
 	}
 
@@ -353,6 +443,13 @@ func (c *Launcher) fetchDependencies(ctx context.Context) error {
 		return err
 	}
 
+	// packages might have been dropped while resolving conflicting versions and
+	// overrides & dependents are only known after everything was resolved
+	instance.Lockfile.SetDependencies(resolver.Resolved)
+	if err := instance.IndexDependencies(ctx); err != nil {
+		return err
+	}
+
 	// TODO: print stats or something
 
 	return nil
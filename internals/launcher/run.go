@@ -37,11 +37,7 @@ func (c *Launcher) Run(opts *instances.LaunchOptions) error {
 		opts.Server = c.ServerMode
 	}
 
-	if c.UseSystemJava {
-		opts.Java = "java"
-	} else {
-		opts.Java = c.java.Bin()
-	}
+	opts.Java = c.javaBin()
 
 	cmd, err := c.Instance.BuildLaunchCmd(opts)
 	if err != nil {
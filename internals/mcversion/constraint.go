@@ -0,0 +1,75 @@
+package mcversion
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// SnapshotLatest is a requirement that matches the newest version, including snapshots
+const SnapshotLatest = "snapshot:latest"
+
+// versionInConstraint matches pre-releases in constraints (eg. ">=1.20.2-pre1") that need to be converted to semver
+var versionInConstraint = regexp.MustCompile(`\d+\.\d+(?:\.\d+)?(?:-pre-?|-rc-?|-snapshot-?)\d+`)
+
+// Constraint is a Minecraft version requirement
+type Constraint struct {
+	raw string
+	// anything matches snapshot:latest
+	any    bool
+	semver *semver.Constraints
+}
+
+// NewConstraint parses a Minecraft version requirement. This can be:
+//   - a semver constraint (eg. ~1.20.1 or >=1.20.2-pre1). Snapshots only match if the constraint contains a pre-release
+//   - an exact version (eg. 23w31a)
+//   - "latest" for the newest release or "snapshot:latest" for the newest version including snapshots
+func NewConstraint(requirement string) (*Constraint, error) {
+	c := &Constraint{raw: requirement}
+	switch requirement {
+	case "":
+		return nil, fmt.Errorf("empty Minecraft requirement")
+	case SnapshotLatest:
+		c.any = true
+		return c, nil
+	case "latest":
+		requirement = "*"
+	}
+
+	converted := versionInConstraint.ReplaceAllStringFunc(requirement, func(id string) string {
+		v, err := Parse(id)
+		if err != nil {
+			return id
+		}
+		return v.semver.String()
+	})
+	semverConstraint, err := semver.NewConstraint(converted)
+	if err == nil {
+		c.semver = semverConstraint
+		return c, nil
+	}
+
+	// exact versions that are not semver (weekly snapshots)
+	if _, parseErr := Parse(requirement); parseErr == nil {
+		return c, nil
+	}
+	return nil, fmt.Errorf("invalid Minecraft requirement %q: %w", requirement, err)
+}
+
+// Check returns true if the version matches this constraint
+func (c *Constraint) Check(v *Version) bool {
+	switch {
+	case c.any || v.ID == c.raw:
+		return true
+	case c.semver == nil || v.semver == nil:
+		return false
+	default:
+		return c.semver.Check(v.semver)
+	}
+}
+
+// String returns the requirement this constraint was created from
+func (c *Constraint) String() string {
+	return c.raw
+}
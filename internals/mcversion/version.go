@@ -0,0 +1,203 @@
+// Package mcversion parses and orders Minecraft versions.
+// Releases, pre-releases, release candidates and snapshots are ordered by their release time
+// (from Mojang's version manifest) or by their version number if the release time is not known.
+package mcversion
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Version types used in Mojang's version manifest
+const (
+	TypeRelease  = "release"
+	TypeSnapshot = "snapshot"
+	TypeOldBeta  = "old_beta"
+	TypeOldAlpha = "old_alpha"
+)
+
+// ErrInvalidVersion is returned if a version id is not a known Minecraft version format
+var ErrInvalidVersion = errors.New("invalid Minecraft version")
+
+var (
+	// 1.20.1 or 26.1
+	releaseVersion = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+	// 1.20.2-pre1, 1.14 Pre-Release 1, 1.21-rc1 or 26.1-snapshot-1
+	preReleaseVersion = regexp.MustCompile(`^(\d+\.\d+(?:\.\d+)?)(-pre-?| Pre-Release |-rc-?|-snapshot-?)(\d+)$`)
+	// 23w31a or 24w14potato
+	weeklySnapshot = regexp.MustCompile(`^\d{2}w\d{2}[a-z_]+$`)
+)
+
+// Version is a single Minecraft version
+type Version struct {
+	// ID is the version as used by Mojang (eg. 1.20.1 or 23w31a)
+	ID string
+	// Type is one of the Type* constants. Pre-releases and release candidates are snapshots
+	Type string
+	// ReleaseTime is only known for versions from Mojang's version manifest
+	ReleaseTime time.Time
+
+	// semver is used to order versions and check constraints.
+	// Weekly snapshots only get one when they are part of a `List`
+	semver *semver.Version
+}
+
+// Parse parses a Minecraft version id. Weekly snapshots (eg. 23w31a) are valid but can only be
+// compared to other versions after they were added to a `List`
+func Parse(id string) (*Version, error) {
+	switch {
+	case releaseVersion.MatchString(id):
+		return &Version{ID: id, Type: TypeRelease, semver: semver.MustParse(id)}, nil
+	case preReleaseVersion.MatchString(id):
+		match := preReleaseVersion.FindStringSubmatch(id)
+		kind := "pre"
+		switch strings.Trim(match[2], "- ") {
+		case "rc":
+			kind = "rc"
+		case "snapshot":
+			// snapshots are released before pre-releases ("0" is lower than any word)
+			kind = "0.snapshot"
+		}
+		return &Version{
+			ID:     id,
+			Type:   TypeSnapshot,
+			semver: semver.MustParse(match[1] + "-" + kind + "." + match[3]),
+		}, nil
+	case weeklySnapshot.MatchString(id):
+		return &Version{ID: id, Type: TypeSnapshot}, nil
+	default:
+		return nil, ErrInvalidVersion
+	}
+}
+
+// String returns the version id
+func (v *Version) String() string {
+	return v.ID
+}
+
+// IsRelease returns true if this is a stable release
+func (v *Version) IsRelease() bool {
+	return v.Type == TypeRelease
+}
+
+// Semver returns the version as semver, snapshots are pre-releases of the release they lead up to.
+// Returns nil if the version can not be expressed as semver (eg. weekly snapshots that are not part of a `List`)
+func (v *Version) Semver() *semver.Version {
+	return v.semver
+}
+
+// Compare returns a negative number if v is older than o, a positive number if it is newer
+// and 0 if both are the same version. The release time is used if it is known for both versions
+func (v *Version) Compare(o *Version) int {
+	switch {
+	case v.ID == o.ID:
+		return 0
+	case !v.ReleaseTime.IsZero() && !o.ReleaseTime.IsZero() && !v.ReleaseTime.Equal(o.ReleaseTime):
+		return v.ReleaseTime.Compare(o.ReleaseTime)
+	case v.semver != nil && o.semver != nil:
+		return v.semver.Compare(o.semver)
+	default:
+		// weekly snapshots of the same year are in order
+		return strings.Compare(v.ID, o.ID)
+	}
+}
+
+// GreaterThan returns true if v is newer than o
+func (v *Version) GreaterThan(o *Version) bool {
+	return v.Compare(o) > 0
+}
+
+// LessThan returns true if v is older than o
+func (v *Version) LessThan(o *Version) bool {
+	return v.Compare(o) < 0
+}
+
+// List contains Minecraft versions ordered from newest to oldest.
+// It is usually created from Mojang's version manifest
+type List struct {
+	versions []*Version
+	byID     map[string]*Version
+}
+
+// NewList sorts the versions by release time. Weekly snapshots are treated as
+// the earliest pre-release of the version that was released after them
+func NewList(versions []*Version) *List {
+	sorted := make([]*Version, len(versions))
+	copy(sorted, versions)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].GreaterThan(sorted[b])
+	})
+
+	list := &List{versions: sorted, byID: make(map[string]*Version, len(sorted))}
+	for _, v := range sorted {
+		list.byID[v.ID] = v
+	}
+
+	// the next (newer) version tells us which release a snapshot leads up to
+	var next *semver.Version
+	unknown := make([]*Version, 0)
+	for _, v := range sorted {
+		switch {
+		case v.semver != nil:
+			next = v.semver
+		case weeklySnapshot.MatchString(v.ID) && next != nil:
+			v.semver = snapshotSemver(next, v.ID)
+		case weeklySnapshot.MatchString(v.ID):
+			unknown = append(unknown, v)
+		}
+	}
+
+	// the newest snapshots lead up to a version that has no pre-release yet
+	if len(unknown) != 0 {
+		if latest := list.LatestRelease(); latest != nil {
+			upcoming := latest.semver.IncPatch()
+			for _, v := range unknown {
+				v.semver = snapshotSemver(&upcoming, v.ID)
+			}
+		}
+	}
+
+	return list
+}
+
+func snapshotSemver(release *semver.Version, id string) *semver.Version {
+	return semver.MustParse(semver.New(release.Major(), release.Minor(), release.Patch(), "", "").String() + "-0." + id)
+}
+
+// Versions returns all versions, newest first
+func (l *List) Versions() []*Version {
+	return l.versions
+}
+
+// Get returns the version with the given id. Versions that are not part of the list are parsed
+func (l *List) Get(id string) (*Version, error) {
+	if v, ok := l.byID[id]; ok {
+		return v, nil
+	}
+	return Parse(id)
+}
+
+// LatestRelease returns the newest stable release or nil if there is none
+func (l *List) LatestRelease() *Version {
+	for _, v := range l.versions {
+		if v.IsRelease() && v.semver != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// Newest returns the newest version that matches the constraint or nil if none matches
+func (l *List) Newest(c *Constraint) *Version {
+	for _, v := range l.versions {
+		if c.Check(v) {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package mcversion
+
+import (
+	"testing"
+	"time"
+)
+
+func testList() *List {
+	day := func(d int) time.Time { return time.Date(2023, 8, d, 0, 0, 0, 0, time.UTC) }
+	versions := []*Version{
+		{ID: "1.20.1", Type: TypeRelease, ReleaseTime: day(1)},
+		{ID: "23w31a", Type: TypeSnapshot, ReleaseTime: day(2)},
+		{ID: "23w33a", Type: TypeSnapshot, ReleaseTime: day(3)},
+		{ID: "1.20.2-pre1", Type: TypeSnapshot, ReleaseTime: day(4)},
+		{ID: "1.20.2-rc1", Type: TypeSnapshot, ReleaseTime: day(5)},
+		{ID: "1.20.2", Type: TypeRelease, ReleaseTime: day(6)},
+		{ID: "23w40a", Type: TypeSnapshot, ReleaseTime: day(7)},
+	}
+	for _, v := range versions {
+		parsed, err := Parse(v.ID)
+		if err != nil {
+			panic(err)
+		}
+		v.semver = parsed.semver
+	}
+	return NewList(versions)
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]string{
+		"1.20.1":             "1.20.1",
+		"1.21":               "1.21.0",
+		"1.20.2-pre1":        "1.20.2-pre.1",
+		"1.14 Pre-Release 2": "1.14.0-pre.2",
+		"1.21-rc1":           "1.21.0-rc.1",
+		"26.1-snapshot-3":    "26.1.0-0.snapshot.3",
+		"26.1-pre-1":         "26.1.0-pre.1",
+	}
+	for id, want := range tests {
+		v, err := Parse(id)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %s", id, err)
+			continue
+		}
+		if v.Semver().String() != want {
+			t.Errorf("Parse(%q) = %s, want %s", id, v.Semver(), want)
+		}
+	}
+
+	if v, err := Parse("23w31a"); err != nil || v.Type != TypeSnapshot || v.Semver() != nil {
+		t.Errorf("unexpected weekly snapshot %+v (%v)", v, err)
+	}
+	if _, err := Parse("b1.7.3"); err != ErrInvalidVersion {
+		t.Errorf("expected ErrInvalidVersion, got %v", err)
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	ordered := []string{"1.13-pre2", "1.13-pre10", "1.13-rc1", "1.13", "1.13.1"}
+	for n := 1; n < len(ordered); n++ {
+		older, _ := Parse(ordered[n-1])
+		newer, _ := Parse(ordered[n])
+		if !newer.GreaterThan(older) || !older.LessThan(newer) {
+			t.Errorf("expected %s to be newer than %s", newer, older)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	list := testList()
+
+	want := []string{"23w40a", "1.20.2", "1.20.2-rc1", "1.20.2-pre1", "23w33a", "23w31a", "1.20.1"}
+	for n, v := range list.Versions() {
+		if v.ID != want[n] {
+			t.Fatalf("unexpected order at %d: got %s, want %s", n, v.ID, want[n])
+		}
+	}
+
+	snapshot, _ := list.Get("23w33a")
+	if snapshot.Semver().String() != "1.20.2-0.23w33a" {
+		t.Errorf("unexpected semver for 23w33a: %s", snapshot.Semver())
+	}
+	upcoming, _ := list.Get("23w40a")
+	if upcoming.Semver().String() != "1.20.3-0.23w40a" {
+		t.Errorf("unexpected semver for 23w40a: %s", upcoming.Semver())
+	}
+	if list.LatestRelease().ID != "1.20.2" {
+		t.Errorf("unexpected latest release %s", list.LatestRelease())
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	list := testList()
+	tests := []struct {
+		requirement string
+		want        string
+	}{
+		{"latest", "1.20.2"},
+		{"*", "1.20.2"},
+		{"~1.20.1", "1.20.2"},
+		{"1.20.1", "1.20.1"},
+		{SnapshotLatest, "23w40a"},
+		{"23w31a", "23w31a"},
+		{"1.20.2-pre1", "1.20.2-pre1"},
+		{"<1.20.2-pre1", "23w33a"},
+		{">=1.20.2-pre1 <1.20.2", "1.20.2-rc1"},
+	}
+	for _, tt := range tests {
+		c, err := NewConstraint(tt.requirement)
+		if err != nil {
+			t.Errorf("NewConstraint(%q) failed: %s", tt.requirement, err)
+			continue
+		}
+		if got := list.Newest(c); got == nil || got.ID != tt.want {
+			t.Errorf("newest version for %q = %v, want %s", tt.requirement, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []string{"", "not a version", ">=23w31a"} {
+		if _, err := NewConstraint(invalid); err == nil {
+			t.Errorf("expected NewConstraint(%q) to fail", invalid)
+		}
+	}
+}
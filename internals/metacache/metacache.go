@@ -0,0 +1,203 @@
+// Package metacache implements a disk backed cache for metadata APIs (Mojang, Fabric, Adoptium …).
+//
+// Responses are reused for the TTL without asking the server. After that they are revalidated
+// with the ETag & Last-Modified headers. Stale responses are used if the server can not be reached.
+package metacache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultTTL is used by caches without a TTL. It can be changed with the "meta.cacheTTL" config option
+var DefaultTTL = 30 * time.Minute
+
+// ErrNotCached is returned in offline mode if a response was never cached
+var ErrNotCached = errors.New("metadata is not cached")
+
+// Cache stores metadata responses in a directory
+type Cache struct {
+	// Dir is the root directory of the cache
+	Dir string
+	// TTL is the time responses are used without revalidating them. DefaultTTL is used if this is 0
+	TTL time.Duration
+	// Offline only returns cached responses (even if they are stale)
+	Offline bool
+	// HTTP is the client used for requests. http.DefaultClient is used if this is nil
+	HTTP *http.Client
+	// Header is added to every request (eg. User-Agent)
+	Header http.Header
+}
+
+// entry is the stored metadata of a cached response. The body is stored next to it
+type entry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+}
+
+// New returns a cache that stores responses in dir
+func New(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+// GetJSON fetches url (or uses the cached response) and decodes the JSON body into v
+func (c *Cache) GetJSON(ctx context.Context, url string, v interface{}) error {
+	body, err := c.Get(ctx, url)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", url, err)
+	}
+	return nil
+}
+
+// Get returns the body of url. It is only fetched if there is no fresh cached response
+func (c *Cache) Get(ctx context.Context, url string) ([]byte, error) {
+	key := c.key(url)
+	cached, body := c.read(key)
+
+	if cached != nil && (c.Offline || time.Since(cached.FetchedAt) < c.ttl()) {
+		return body, nil
+	}
+	if c.Offline {
+		return nil, fmt.Errorf("%w: %s", ErrNotCached, url)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range c.Header {
+		req.Header[name] = values
+	}
+	if cached != nil && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	if cached != nil && cached.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+
+	res, err := c.client().Do(req)
+	if err != nil {
+		if cached != nil && ctx.Err() == nil {
+			log.Printf("Using stale metadata for %s: %s", url, err)
+			return body, nil
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotModified && cached != nil:
+		cached.FetchedAt = time.Now()
+		if err := c.writeEntry(key, cached); err != nil {
+			log.Printf("Could not update metadata cache: %s", err)
+		}
+		return body, nil
+	case res.StatusCode == http.StatusOK:
+		fresh, err := io.ReadAll(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		stored := &entry{
+			URL:          url,
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+			FetchedAt:    time.Now(),
+		}
+		if err := c.write(key, stored, fresh); err != nil {
+			log.Printf("Could not update metadata cache: %s", err)
+		}
+		return fresh, nil
+	case res.StatusCode >= 500 && cached != nil:
+		log.Printf("Using stale metadata for %s: server responded with %s", url, res.Status)
+		return body, nil
+	default:
+		return nil, fmt.Errorf("%s did respond with unexpected status %s", url, res.Status)
+	}
+}
+
+func (c *Cache) ttl() time.Duration {
+	if c.TTL == 0 {
+		return DefaultTTL
+	}
+	return c.TTL
+}
+
+func (c *Cache) client() *http.Client {
+	if c.HTTP == nil {
+		return http.DefaultClient
+	}
+	return c.HTTP
+}
+
+func (c *Cache) key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+// read returns the cached entry and body or nil if the url is not cached
+func (c *Cache) read(key string) (*entry, []byte) {
+	rawEntry, err := os.ReadFile(filepath.Join(c.Dir, key+".json"))
+	if err != nil {
+		return nil, nil
+	}
+	cached := &entry{}
+	if err := json.Unmarshal(rawEntry, cached); err != nil {
+		return nil, nil
+	}
+	body, err := os.ReadFile(filepath.Join(c.Dir, key))
+	if err != nil {
+		return nil, nil
+	}
+	return cached, body
+}
+
+func (c *Cache) write(key string, cached *entry, body []byte) error {
+	if err := os.MkdirAll(c.Dir, os.ModePerm); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(c.Dir, key), body); err != nil {
+		return err
+	}
+	return c.writeEntry(key, cached)
+}
+
+func (c *Cache) writeEntry(key string, cached *entry) error {
+	rawEntry, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(c.Dir, key+".json"), rawEntry)
+}
+
+// writeFileAtomic writes to a temporary file first, so concurrent readers never see partial files
+func writeFileAtomic(p string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
@@ -0,0 +1,99 @@
+package metacache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCache_Get(t *testing.T) {
+	requests := 0
+	revalidated := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("User-Agent") != "minepkg-test" {
+			t.Errorf("missing header, got user agent %q", r.Header.Get("User-Agent"))
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"version": 1}`))
+	}))
+	url := server.URL + "/versions.json"
+
+	cache := New(t.TempDir())
+	cache.Header = http.Header{"User-Agent": []string{"minepkg-test"}}
+	ctx := context.Background()
+
+	get := func() int {
+		t.Helper()
+		parsed := struct{ Version int }{}
+		if err := cache.GetJSON(ctx, url, &parsed); err != nil {
+			t.Fatal(err)
+		}
+		return parsed.Version
+	}
+
+	if get() != 1 || requests != 1 {
+		t.Fatalf("expected one request, got %d", requests)
+	}
+	// fresh responses are used without a request
+	if get() != 1 || requests != 1 {
+		t.Fatalf("expected the cached response to be used, got %d requests", requests)
+	}
+
+	// stale responses are revalidated with the etag
+	cache.TTL = time.Nanosecond
+	if get() != 1 || revalidated != 1 {
+		t.Fatalf("expected the response to be revalidated, got %d requests", requests)
+	}
+
+	// the cached response is used if the server is down
+	server.Close()
+	if get() != 1 {
+		t.Fatal("expected the stale response")
+	}
+
+	cache.Offline = true
+	if get() != 1 {
+		t.Fatal("expected the cached response in offline mode")
+	}
+	if _, err := cache.Get(ctx, server.URL+"/unknown.json"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("expected ErrNotCached, got %v", err)
+	}
+}
+
+func TestCache_Get_lastModified(t *testing.T) {
+	modified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == modified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Last-Modified", modified)
+		w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	cache := &Cache{Dir: t.TempDir(), TTL: time.Nanosecond}
+	for n := 0; n < 2; n++ {
+		body, err := cache.Get(context.Background(), server.URL+"/file")
+		if err != nil || string(body) != "content" {
+			t.Fatalf("unexpected response %q (%v)", body, err)
+		}
+	}
+
+	if _, err := cache.Get(context.Background(), server.URL+"/missing"); err == nil {
+		t.Error("expected an error for a 404 response")
+	}
+}
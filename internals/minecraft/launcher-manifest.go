@@ -24,6 +24,9 @@ type LaunchManifest struct {
 	Arguments *Arguments `json:"arguments,omitempty"`
 	// MainClass is the main class to launch (eg. net.minecraft.client.main.Main) – modded versions (like fabric) usually override this
 	MainClass string `json:"mainClass"`
+	// ServerArgs are passed to the main class when launching a server. This is not part of the official format,
+	// minepkg sets it for loaders that launch servers differently (eg. forge)
+	ServerArgs []string `json:"serverArgs,omitempty"`
 
 	// Downloads contains the client and server artifacts (jar files)
 	// Newer versions also contain the mappings txt files (client_mappings and server_mappings)
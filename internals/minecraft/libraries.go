@@ -20,6 +20,8 @@ type Library struct {
 		Classifiers map[string]Artifact `json:"classifiers"`
 	} `json:"downloads,omitempty"`
 	URL string `json:"url"`
+	// Sha1 is set by some loaders (eg. fabric) for libraries without `Downloads`
+	Sha1 string `json:"sha1,omitempty"`
 	// Rules is a list of rules that determine whether this library should be included.
 	// If no rules are specified, the library is included by default.
 	Rules []Rule `json:"rules"`
@@ -65,6 +67,12 @@ func (l *Library) Filepath() string {
 	return libPath
 }
 
+// IsLocal returns true if the library can not be downloaded because it is created or
+// bundled by a mod loader installer (eg. the patched Minecraft jar of NeoForge)
+func (l *Library) IsLocal() bool {
+	return len(l.Natives) == 0 && l.URL == "" && l.Downloads.Artifact.URL == "" && l.Downloads.Artifact.Path != ""
+}
+
 // DownloadURL returns the Download URL this library
 func (l *Library) DownloadURL() string {
 	osName := runtime.GOOS
@@ -89,6 +97,25 @@ func (l *Library) DownloadURL() string {
 	}
 }
 
+// DownloadSha1 returns the sha1 hash of the file returned by [Library.DownloadURL].
+// It is empty if the hash is unknown
+func (l *Library) DownloadSha1() string {
+	osName := runtime.GOOS
+	if osName == "darwin" {
+		osName = "osx"
+	}
+
+	switch {
+	case l.Natives[osName] != "":
+		nativeID := l.Natives[osName]
+		return l.Downloads.Classifiers[nativeID].Sha1
+	case l.Downloads.Artifact.URL != "":
+		return l.Downloads.Artifact.Sha1
+	default:
+		return l.Sha1
+	}
+}
+
 // RequiredLibraries returns a slice of libraries that are required for the current platform
 func RequiredLibraries(libraries []Library) []Library {
 	required := make([]Library, 0)
@@ -41,6 +41,16 @@ func New(httpClient *http.Client) *Client {
 	}
 }
 
+// SetBaseURL changes the api url used by this client (eg. for testing against a local server)
+func (c *Client) SetBaseURL(baseURL string) error {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return err
+	}
+	c.baseURL = parsed
+	return nil
+}
+
 // url joins the addedPath to the baseURL (panics if new path can not be parsed)
 func (c *Client) url(addedPath ...string) *url.URL {
 	joined, err := url.JoinPath(c.baseURL.String(), addedPath...)
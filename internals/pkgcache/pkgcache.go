@@ -0,0 +1,388 @@
+// Package pkgcache implements the global package cache.
+//
+// Packages are stored once by their content (sha256) in the blobs directory. A small index maps
+// lock entries (provider, name & version) to those blobs, so the same jar fetched through
+// different providers or for multiple instances is only stored once.
+package pkgcache
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+// IndexVersion is the current version of the index file format
+const IndexVersion = 1
+
+// ErrNotCached is returned if a lock entry has no blob in the cache
+var ErrNotCached = errors.New("package is not cached")
+
+// TempMaxAge is the age after which GC removes temporary downloads. Younger ones might still be
+// in use by another minepkg process (or resumed by the next one)
+var TempMaxAge = 24 * time.Hour
+
+// staleLockAge is the age after which the index lock is considered abandoned (eg. the process crashed)
+const staleLockAge = time.Minute
+
+// Cache is a content addressed package cache. It can be shared by multiple processes
+type Cache struct {
+	// Dir is the root directory of the cache
+	Dir string
+
+	// mu only guards this process, the lock file guards the index across processes
+	mu sync.Mutex
+}
+
+// IndexEntry maps a lock entry to a blob
+type IndexEntry struct {
+	// Blob is the content hash in the "sha256:<hex>" format
+	Blob string `json:"blob"`
+	// Size of the blob in bytes
+	Size int64 `json:"size"`
+	// StoredAt is the time the entry was added
+	StoredAt time.Time `json:"storedAt"`
+}
+
+// Index is the persisted mapping of lock keys to blobs
+type Index struct {
+	Version int                    `json:"version"`
+	Entries map[string]*IndexEntry `json:"entries"`
+}
+
+// Stats describes the current state of the cache
+type Stats struct {
+	// Blobs is the number of stored files
+	Blobs int `json:"blobs"`
+	// Size is the combined size of all blobs in bytes
+	Size int64 `json:"size"`
+	// Entries is the number of lock entries in the index
+	Entries int `json:"entries"`
+	// Unindexed is the number of blobs that no index entry points to
+	Unindexed int `json:"unindexed"`
+}
+
+// GCResult describes what was removed by a garbage collection
+type GCResult struct {
+	RemovedBlobs   int   `json:"removedBlobs"`
+	RemovedEntries int   `json:"removedEntries"`
+	FreedBytes     int64 `json:"freedBytes"`
+}
+
+// New returns a cache that lives in the given directory
+func New(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+// Key returns the index key for a lock entry (eg. "modrinth:sodium@AbCd123")
+func Key(lock *manifest.DependencyLock) string {
+	provider := lock.Provider
+	if provider == "" {
+		provider = "minepkg"
+	}
+	return fmt.Sprintf("%s:%s@%s", provider, lock.Name, lock.Version)
+}
+
+func (c *Cache) indexPath() string {
+	return filepath.Join(c.Dir, "index.json")
+}
+
+func (c *Cache) lockPath() string {
+	return filepath.Join(c.Dir, "index.lock")
+}
+
+func (c *Cache) blobsDir() string {
+	return filepath.Join(c.Dir, "blobs", "sha256")
+}
+
+// TempDir returns a directory for downloads that are not stored yet. It is on the same
+// file system as the blobs, so files can be moved into the cache
+func (c *Cache) TempDir() string {
+	return filepath.Join(c.Dir, "tmp")
+}
+
+func (c *Cache) blobPath(blob string) string {
+	hex := strings.TrimPrefix(blob, "sha256:")
+	return filepath.Join(c.blobsDir(), hex[:2], hex)
+}
+
+func (c *Cache) readIndex() (*Index, error) {
+	index := &Index{Version: IndexVersion, Entries: make(map[string]*IndexEntry)}
+
+	raw, err := os.ReadFile(c.indexPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, index); err != nil {
+		return nil, fmt.Errorf("package cache index is corrupted: %w", err)
+	}
+	if index.Entries == nil {
+		index.Entries = make(map[string]*IndexEntry)
+	}
+	return index, nil
+}
+
+func (c *Cache) writeIndex(index *Index) error {
+	if err := os.MkdirAll(c.Dir, os.ModePerm); err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so a crash never leaves a broken index behind
+	tmp := c.indexPath() + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.indexPath())
+}
+
+// lock acquires the index lock that is shared by all processes using this cache.
+// It has to be held for every read-modify-write of the index. The returned function releases it
+func (c *Cache) lock() (func(), error) {
+	c.mu.Lock()
+	if err := os.MkdirAll(c.Dir, os.ModePerm); err != nil {
+		c.mu.Unlock()
+		return nil, err
+	}
+
+	for {
+		f, err := os.OpenFile(c.lockPath(), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() {
+				os.Remove(c.lockPath())
+				c.mu.Unlock()
+			}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			c.mu.Unlock()
+			return nil, err
+		}
+		// another process holds the lock. it is taken over if that process seems to be gone
+		if info, err := os.Stat(c.lockPath()); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(c.lockPath())
+			continue
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Path returns the path of the cached file for the lock entry
+// or ErrNotCached if it is not in the cache
+func (c *Cache) Path(lock *manifest.DependencyLock) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index, err := c.readIndex()
+	if err != nil {
+		return "", err
+	}
+
+	entry, ok := index.Entries[Key(lock)]
+	if !ok {
+		return "", ErrNotCached
+	}
+
+	p := c.blobPath(entry.Blob)
+	if _, err := os.Stat(p); err != nil {
+		return "", ErrNotCached
+	}
+	return p, nil
+}
+
+// Store moves the file at src into the cache and adds an index entry for the lock entry.
+// If the same content is already cached, src is removed instead. Returns the path of the blob
+func (c *Cache) Store(lock *manifest.DependencyLock, src string) (string, error) {
+	blob, size, err := hashFile(src)
+	if err != nil {
+		return "", err
+	}
+
+	unlock, err := c.lock()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	target := c.blobPath(blob)
+	if _, err := os.Stat(target); err == nil {
+		os.Remove(src)
+	} else {
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return "", err
+		}
+		if err := os.Rename(src, target); err != nil {
+			return "", err
+		}
+	}
+
+	index, err := c.readIndex()
+	if err != nil {
+		return "", err
+	}
+	index.Entries[Key(lock)] = &IndexEntry{Blob: blob, Size: size, StoredAt: time.Now().UTC()}
+	if err := c.writeIndex(index); err != nil {
+		return "", err
+	}
+
+	return target, nil
+}
+
+// Stats returns the number of blobs & entries and the size of the cache
+func (c *Cache) Stats() (*Stats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index, err := c.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	indexed := make(map[string]bool, len(index.Entries))
+	for _, entry := range index.Entries {
+		indexed[strings.TrimPrefix(entry.Blob, "sha256:")] = true
+	}
+
+	stats := &Stats{Entries: len(index.Entries)}
+	err = c.walkBlobs(func(hex string, p string, info fs.FileInfo) error {
+		stats.Blobs++
+		stats.Size += info.Size()
+		if !indexed[hex] {
+			stats.Unindexed++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GC removes all index entries that are not in keep (see Key) and all blobs that are no longer
+// referenced by an index entry. Temporary downloads older than TempMaxAge are removed as well
+func (c *Cache) GC(keep map[string]bool) (*GCResult, error) {
+	unlock, err := c.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	index, err := c.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GCResult{}
+	referenced := make(map[string]bool)
+	for key, entry := range index.Entries {
+		if !keep[key] {
+			delete(index.Entries, key)
+			result.RemovedEntries++
+			continue
+		}
+		referenced[strings.TrimPrefix(entry.Blob, "sha256:")] = true
+	}
+
+	if err := c.writeIndex(index); err != nil {
+		return nil, err
+	}
+
+	err = c.walkBlobs(func(hex string, p string, info fs.FileInfo) error {
+		if referenced[hex] {
+			return nil
+		}
+		if err := os.Remove(p); err != nil {
+			return err
+		}
+		result.RemovedBlobs++
+		result.FreedBytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.removeTempFiles(time.Now().Add(-TempMaxAge), result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// removeTempFiles removes temporary downloads that were last modified before the given time
+func (c *Cache) removeTempFiles(before time.Time, result *GCResult) error {
+	entries, err := os.ReadDir(c.TempDir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(before) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(c.TempDir(), entry.Name())); err != nil {
+			return err
+		}
+		result.FreedBytes += info.Size()
+	}
+	return nil
+}
+
+// walkBlobs calls fn for every stored blob
+func (c *Cache) walkBlobs(fn func(hex string, p string, info fs.FileInfo) error) error {
+	err := filepath.WalkDir(c.blobsDir(), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(d.Name(), p, info)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// hashFile returns the blob name ("sha256:<hex>") and size of a file
+func hashFile(p string) (string, int64, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, f)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return fmt.Sprintf("sha256:%x", hasher.Sum(nil)), size, nil
+}
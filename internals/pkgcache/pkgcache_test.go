@@ -0,0 +1,135 @@
+package pkgcache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+func writeTemp(t *testing.T, dir string, content string) string {
+	t.Helper()
+	f, err := os.CreateTemp(dir, "download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestCache_deduplicatesContent(t *testing.T) {
+	cache := New(filepath.Join(t.TempDir(), "packages"))
+	tmp := t.TempDir()
+
+	fromModrinth := &manifest.DependencyLock{Name: "sodium", Version: "AbCd", Provider: "modrinth"}
+	fromCurseForge := &manifest.DependencyLock{Name: "sodium", Version: "4711", Provider: "curseforge"}
+
+	a, err := cache.Store(fromModrinth, writeTemp(t, tmp, "same jar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := cache.Store(fromCurseForge, writeTemp(t, tmp, "same jar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("expected the same blob for the same content, got %s and %s", a, b)
+	}
+
+	if p, err := cache.Path(fromCurseForge); err != nil || p != a {
+		t.Errorf("expected %s to be cached at %s, got %s (%v)", Key(fromCurseForge), a, p, err)
+	}
+
+	stats, err := cache.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Blobs != 1 || stats.Entries != 2 {
+		t.Errorf("expected 1 blob and 2 entries, got %d and %d", stats.Blobs, stats.Entries)
+	}
+}
+
+func TestCache_GC(t *testing.T) {
+	cache := New(filepath.Join(t.TempDir(), "packages"))
+	tmp := t.TempDir()
+
+	used := &manifest.DependencyLock{Name: "used", Version: "1.0.0"}
+	unused := &manifest.DependencyLock{Name: "unused", Version: "1.0.0"}
+	if _, err := cache.Store(used, writeTemp(t, tmp, "used jar")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Store(unused, writeTemp(t, tmp, "unused jar")); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := cache.GC(map[string]bool{Key(used): true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RemovedBlobs != 1 || result.RemovedEntries != 1 || result.FreedBytes != int64(len("unused jar")) {
+		t.Errorf("unexpected gc result %+v", result)
+	}
+
+	if _, err := cache.Path(used); err != nil {
+		t.Errorf("expected used package to be kept, got %v", err)
+	}
+	if _, err := cache.Path(unused); err != ErrNotCached {
+		t.Errorf("expected unused package to be removed, got %v", err)
+	}
+}
+
+func TestCache_GC_keepsRecentTempFiles(t *testing.T) {
+	cache := New(filepath.Join(t.TempDir(), "packages"))
+	os.MkdirAll(cache.TempDir(), os.ModePerm)
+
+	inFlight := filepath.Join(cache.TempDir(), "in-flight.jar.part")
+	abandoned := filepath.Join(cache.TempDir(), "abandoned.jar.part")
+	os.WriteFile(inFlight, []byte("downloading"), 0644)
+	os.WriteFile(abandoned, []byte("crashed"), 0644)
+	old := time.Now().Add(-2 * TempMaxAge)
+	os.Chtimes(abandoned, old, old)
+
+	if _, err := cache.GC(map[string]bool{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(inFlight); err != nil {
+		t.Errorf("expected the download of another process to be kept, got %v", err)
+	}
+	if _, err := os.Stat(abandoned); !os.IsNotExist(err) {
+		t.Errorf("expected the abandoned download to be removed, got %v", err)
+	}
+}
+
+func TestCache_Store_sharedIndex(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "packages")
+	tmp := t.TempDir()
+
+	// every cache stands in for a separate minepkg process using the same directory
+	var wg sync.WaitGroup
+	locks := make([]*manifest.DependencyLock, 20)
+	for n := range locks {
+		locks[n] = &manifest.DependencyLock{Name: fmt.Sprintf("mod-%d", n), Version: "1.0.0"}
+		src := writeTemp(t, tmp, locks[n].Name)
+		wg.Add(1)
+		go func(lock *manifest.DependencyLock) {
+			defer wg.Done()
+			if _, err := New(dir).Store(lock, src); err != nil {
+				t.Error(err)
+			}
+		}(locks[n])
+	}
+	wg.Wait()
+
+	for _, lock := range locks {
+		if _, err := New(dir).Path(lock); err != nil {
+			t.Errorf("%s: expected the index entry to survive concurrent writes, got %v", lock.Name, err)
+		}
+	}
+}
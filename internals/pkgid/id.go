@@ -40,6 +40,24 @@ func parseIt(id string, likeVersion bool) *ID {
 		return newId
 	}
 
+	// special case for local files (paths can contain "/" and "@")
+	if strings.HasPrefix(id, "file:") {
+		newId.Provider = "file"
+		newId.Version = strings.TrimPrefix(id, "file:")
+		return newId
+	}
+
+	// special case for github (name is owner/repo)
+	if strings.HasPrefix(id, "github:") {
+		newId.Provider = "github"
+		parts := strings.SplitN(strings.TrimPrefix(id, "github:"), "@", 2)
+		newId.Name = parts[0]
+		if len(parts) == 2 {
+			newId.Version = parts[1]
+		}
+		return newId
+	}
+
 	parts := strings.SplitN(id, ":", 2)
 	if len(parts) == 2 {
 		newId.Provider = parts[0]
@@ -0,0 +1,331 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/minepkg/minepkg/internals/curseforge"
+	"github.com/minepkg/minepkg/internals/ownhttp"
+	"github.com/minepkg/minepkg/internals/pkgid"
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+var (
+	ErrCurseForgeVersionNotSupported = errors.New("for curseforge version can only be '*', 'latest' or a file id")
+	ErrCurseForgeNoDownload          = errors.New("the author of this mod does not allow downloads outside of curseforge")
+)
+
+// ErrCurseForgeFileMismatch is returned if a file that was requested by id does not work with the requirements
+type ErrCurseForgeFileMismatch struct {
+	File   *curseforge.File
+	Reason string
+}
+
+func (e *ErrCurseForgeFileMismatch) Error() string {
+	return fmt.Sprintf("curseforge file %d (%s) %s", e.File.ID, e.File.DisplayName, e.Reason)
+}
+
+type CurseForgeProvider struct {
+	Client *curseforge.Client
+
+	// slugs caches mod id -> slug lookups
+	slugs sync.Map
+}
+
+type curseForgeResult struct {
+	name              string
+	file              *curseforge.File
+	dependencies      []*manifest.InterpretedDependency
+	incompatibilities []*manifest.InterpretedDependency
+}
+
+func (c *curseForgeResult) Lock() *manifest.DependencyLock {
+	return &manifest.DependencyLock{
+		Name:        c.name,
+		Version:     strconv.Itoa(c.file.ID),
+		VersionName: c.file.DisplayName,
+		Type:        "mod",
+		URL:         c.file.DownloadURL,
+		Provider:    "curseforge",
+		Sha1:        c.file.Sha1(),
+		Size:        c.file.FileLength,
+	}
+}
+
+// Dependencies returns the required dependencies of this file
+func (c *curseForgeResult) Dependencies() []*manifest.InterpretedDependency {
+	return c.dependencies
+}
+
+// Incompatibilities returns the mods this file is declared incompatible with
+func (c *curseForgeResult) Incompatibilities() []*manifest.InterpretedDependency {
+	return c.incompatibilities
+}
+
+func NewCurseForgeProvider() *CurseForgeProvider {
+	client := http.Client{Transport: ownhttp.NewAddHeaderTransport(nil)}
+
+	return &CurseForgeProvider{
+		Client: curseforge.New(&client),
+	}
+}
+
+func (c *CurseForgeProvider) Name() string { return "curseforge" }
+
+func (c *CurseForgeProvider) Resolve(ctx context.Context, request *Request) (Result, error) {
+	if request.Dependency.Version == "*" || request.Dependency.Version == "latest" || request.Dependency.Version == "" {
+		return c.ResolveLatest(ctx, request)
+	}
+
+	fileID, err := strconv.Atoi(request.Dependency.Version)
+	if err != nil {
+		return nil, ErrCurseForgeVersionNotSupported
+	}
+
+	mod, err := c.Client.GetModByIDOrSlug(ctx, request.Dependency.Name)
+	if err != nil {
+		return nil, err
+	}
+	c.slugs.Store(mod.ID, mod.Slug)
+
+	file, err := c.Client.GetModFile(ctx, mod.ID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCurseForgeFile(file, request.Requirements); err != nil {
+		return nil, err
+	}
+
+	return c.newResult(ctx, request.Dependency.Name, file)
+}
+
+func (c *CurseForgeProvider) ResolveLatest(ctx context.Context, request *Request) (Result, error) {
+	mod, err := c.Client.GetModByIDOrSlug(ctx, request.Dependency.Name)
+	if err != nil {
+		return nil, err
+	}
+	c.slugs.Store(mod.ID, mod.Slug)
+
+	if request.Requirements == nil {
+		return c.latestFile(ctx, request.Dependency.Name, mod.ID, &curseforge.ListModFilesQuery{})
+	}
+
+	for _, loader := range compatibleLoaders(request.Requirements.PlatformName()) {
+		query := &curseforge.ListModFilesQuery{
+			GameVersion:   request.Requirements.MinecraftVersion(),
+			ModLoaderType: curseForgeLoader(loader),
+		}
+		result, err := c.latestFile(ctx, request.Dependency.Name, mod.ID, query)
+		if err != ErrCouldNotFindLatestVersion {
+			return result, err
+		}
+	}
+
+	return nil, ErrCouldNotFindLatestVersion
+}
+
+// latestFile returns the newest available file of the mod that matches the query
+func (c *CurseForgeProvider) latestFile(ctx context.Context, name string, modID int, query *curseforge.ListModFilesQuery) (*curseForgeResult, error) {
+	files, err := c.Client.ListModFiles(ctx, modID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// the api filter is not always reliable, so we check the game versions again
+	var latest *curseforge.File
+	for i, file := range files {
+		if !file.IsAvailable || !curseForgeFileMatches(&file, query) {
+			continue
+		}
+		if latest == nil || file.FileDate.After(latest.FileDate) {
+			latest = &files[i]
+		}
+	}
+
+	if latest == nil {
+		return nil, ErrCouldNotFindLatestVersion
+	}
+
+	return c.newResult(ctx, name, latest)
+}
+
+func (c *CurseForgeProvider) newResult(ctx context.Context, name string, file *curseforge.File) (*curseForgeResult, error) {
+	if file.DownloadURL == "" {
+		return nil, ErrCurseForgeNoDownload
+	}
+
+	result := &curseForgeResult{
+		name: name,
+		file: file,
+	}
+
+	for _, dependency := range file.Dependencies {
+		switch dependency.RelationType {
+		case curseforge.RelationRequiredDependency, curseforge.RelationIncompatible:
+		default:
+			// optional, embedded and tool dependencies are not installed
+			continue
+		}
+
+		slug, err := c.modSlug(ctx, dependency.ModID)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve dependency of %s: %w", name, err)
+		}
+
+		id := &pkgid.ID{Provider: "curseforge", Name: slug, Version: "latest"}
+		interpreted := &manifest.InterpretedDependency{
+			Provider: id.Provider,
+			Name:     slug,
+			Source:   id.Version,
+			ID:       id,
+		}
+
+		if dependency.RelationType == curseforge.RelationRequiredDependency {
+			result.dependencies = append(result.dependencies, interpreted)
+		} else {
+			result.incompatibilities = append(result.incompatibilities, interpreted)
+		}
+	}
+
+	return result, nil
+}
+
+// modSlug returns the slug for the given mod id
+func (c *CurseForgeProvider) modSlug(ctx context.Context, modID int) (string, error) {
+	if slug, ok := c.slugs.Load(modID); ok {
+		return slug.(string), nil
+	}
+
+	mod, err := c.Client.GetMod(ctx, modID)
+	if err != nil {
+		return "", err
+	}
+
+	c.slugs.Store(modID, mod.Slug)
+	return mod.Slug, nil
+}
+
+func (c *CurseForgeProvider) CanConvertURL(url string) bool {
+	return strings.HasPrefix(url, "https://www.curseforge.com/minecraft/mc-mods/") ||
+		strings.HasPrefix(url, "https://curseforge.com/minecraft/mc-mods/")
+}
+
+func (c *CurseForgeProvider) ConvertURL(ctx context.Context, rawURL string) (string, error) {
+	if !c.CanConvertURL(rawURL) {
+		return "", fmt.Errorf("url %s is not a curseforge mod url", rawURL)
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	// url looks like https://www.curseforge.com/minecraft/mc-mods/jei/files/4593548
+	// or https://www.curseforge.com/minecraft/mc-mods/jei/download/4593548
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) < 3 || parts[2] == "" {
+		return "", fmt.Errorf("url %s does not contain a mod", rawURL)
+	}
+	slug := parts[2]
+
+	if len(parts) < 5 || (parts[3] != "files" && parts[3] != "download") {
+		// no file in url, we use the latest file
+		return fmt.Sprintf("curseforge:%s@latest", slug), nil
+	}
+
+	fileID, err := strconv.Atoi(parts[4])
+	if err != nil {
+		return "", fmt.Errorf("url %s does not contain a valid file id", rawURL)
+	}
+
+	// make sure that the file exists
+	mod, err := c.Client.GetModBySlug(ctx, slug)
+	if err != nil {
+		return "", err
+	}
+	if _, err := c.Client.GetModFile(ctx, mod.ID, fileID); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("curseforge:%s@%d", slug, fileID), nil
+}
+
+// curseForgeLoader maps a minepkg platform to a curseforge mod loader
+func curseForgeLoader(platform string) curseforge.ModLoaderType {
+	switch platform {
+	case "fabric":
+		return curseforge.ModLoaderFabric
+	case "quilt":
+		return curseforge.ModLoaderQuilt
+	case "neoforge":
+		return curseforge.ModLoaderNeoForge
+	case "forge":
+		return curseforge.ModLoaderForge
+	default:
+		return curseforge.ModLoaderAny
+	}
+}
+
+var curseForgeLoaderNames = map[string]curseforge.ModLoaderType{
+	"forge":    curseforge.ModLoaderForge,
+	"fabric":   curseforge.ModLoaderFabric,
+	"quilt":    curseforge.ModLoaderQuilt,
+	"neoforge": curseforge.ModLoaderNeoForge,
+}
+
+// checkCurseForgeFile applies the checks of latestFile to a file that was requested by id
+func checkCurseForgeFile(file *curseforge.File, requirements manifest.PlatformLock) error {
+	if !file.IsAvailable {
+		return &ErrCurseForgeFileMismatch{file, "is not available"}
+	}
+	if requirements == nil {
+		return nil
+	}
+
+	for _, loader := range compatibleLoaders(requirements.PlatformName()) {
+		query := &curseforge.ListModFilesQuery{
+			GameVersion:   requirements.MinecraftVersion(),
+			ModLoaderType: curseForgeLoader(loader),
+		}
+		if curseForgeFileMatches(file, query) {
+			return nil
+		}
+	}
+	return &ErrCurseForgeFileMismatch{file, fmt.Sprintf(
+		"does not support Minecraft %s with %s (it lists %s)",
+		requirements.MinecraftVersion(),
+		requirements.PlatformName(),
+		strings.Join(file.GameVersions, ", "),
+	)}
+}
+
+// curseForgeFileMatches returns true if the file supports the queried minecraft version and loader
+func curseForgeFileMatches(file *curseforge.File, query *curseforge.ListModFilesQuery) bool {
+	versionMatches := query.GameVersion == ""
+	listsLoader := false
+	loaderMatches := false
+
+	// game versions contain minecraft versions and loaders (eg. ["1.20.1", "Fabric", "Client"])
+	for _, gameVersion := range file.GameVersions {
+		if gameVersion == query.GameVersion {
+			versionMatches = true
+		}
+		if loader, ok := curseForgeLoaderNames[strings.ToLower(gameVersion)]; ok {
+			listsLoader = true
+			loaderMatches = loaderMatches || loader == query.ModLoaderType
+		}
+	}
+
+	// old files do not list any loader, so we can only trust the api filter for them
+	if query.ModLoaderType == curseforge.ModLoaderAny || !listsLoader {
+		loaderMatches = true
+	}
+
+	return versionMatches && loaderMatches
+}
@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/minepkg/minepkg/internals/curseforge"
+	"github.com/minepkg/minepkg/internals/pkgid"
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+// newCurseForgeTestServer serves a tiny subset of the curseforge api
+func newCurseForgeTestServer(t *testing.T) *httptest.Server {
+	jei := curseforge.Mod{ID: 1, Slug: "jei", ClassID: 6}
+	lib := curseforge.Mod{ID: 2, Slug: "some-lib", ClassID: 6}
+
+	files := []curseforge.File{
+		{
+			ID:           100,
+			ModID:        1,
+			DisplayName:  "jei-1.20.1-fabric",
+			DownloadURL:  "https://example.com/jei-fabric.jar",
+			GameVersions: []string{"1.20.1", "Fabric"},
+			Hashes:       []curseforge.FileHash{{Value: "md5", Algo: curseforge.HashAlgoMd5}, {Value: "abc", Algo: curseforge.HashAlgoSha1}},
+			Dependencies: []curseforge.FileDependency{{ModID: 2, RelationType: curseforge.RelationRequiredDependency}},
+			IsAvailable:  true,
+		},
+		{
+			ID:           101,
+			ModID:        1,
+			DisplayName:  "jei-1.20.1-forge",
+			DownloadURL:  "https://example.com/jei-forge.jar",
+			GameVersions: []string{"1.20.1", "Forge"},
+			IsAvailable:  true,
+		},
+		{
+			ID:           102,
+			ModID:        1,
+			DisplayName:  "jei-1.20.1-forge-withdrawn",
+			DownloadURL:  "https://example.com/jei-forge-withdrawn.jar",
+			GameVersions: []string{"1.20.1", "Forge"},
+		},
+	}
+
+	mux := http.NewServeMux()
+	respond := func(w http.ResponseWriter, data interface{}) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}
+	mux.HandleFunc("/v1/mods/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "test-key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		respond(w, []curseforge.Mod{jei})
+	})
+	mux.HandleFunc("/v1/mods/1/files", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, files)
+	})
+	mux.HandleFunc("/v1/mods/1/files/101", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, files[1])
+	})
+	mux.HandleFunc("/v1/mods/1/files/102", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, files[2])
+	})
+	mux.HandleFunc("/v1/mods/2", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, lib)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestCurseForgeProvider(t *testing.T) *CurseForgeProvider {
+	server := newCurseForgeTestServer(t)
+	provider := &CurseForgeProvider{Client: curseforge.New(nil)}
+	provider.Client.APIKey = "test-key"
+	if err := provider.Client.SetBaseURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestCurseForgeProvider_Resolve(t *testing.T) {
+	tests := []struct {
+		name         string
+		version      string
+		requirements manifest.PlatformLock
+		wantVersion  string
+		wantSha1     string
+		wantDeps     int
+	}{
+		{"latest for fabric", "latest", &manifest.FabricLock{Minecraft: "1.20.1"}, "100", "abc", 1},
+		{"latest for quilt falls back to fabric", "latest", &manifest.QuiltLock{Minecraft: "1.20.1"}, "100", "abc", 1},
+		{"file id", "101", &manifest.ForgeLock{Minecraft: "1.20.1"}, "101", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestCurseForgeProvider(t)
+			res, err := provider.Resolve(context.Background(), &Request{
+				Dependency:   &pkgid.ID{Provider: "curseforge", Name: "jei", Version: tt.version},
+				Requirements: tt.requirements,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lock := res.Lock()
+			if lock.Version != tt.wantVersion || lock.Sha1 != tt.wantSha1 {
+				t.Errorf("got version %s (sha1 %q), want %s (sha1 %q)", lock.Version, lock.Sha1, tt.wantVersion, tt.wantSha1)
+			}
+			if len(res.Dependencies()) != tt.wantDeps {
+				t.Fatalf("got %d dependencies, want %d", len(res.Dependencies()), tt.wantDeps)
+			}
+			if tt.wantDeps > 0 && res.Dependencies()[0].Name != "some-lib" {
+				t.Errorf("expected dependency some-lib, got %s", res.Dependencies()[0].Name)
+			}
+		})
+	}
+}
+
+func TestCurseForgeProvider_Resolve_pinnedMismatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		version      string
+		requirements manifest.PlatformLock
+	}{
+		{"wrong loader", "101", &manifest.FabricLock{Minecraft: "1.20.1"}},
+		{"wrong minecraft version", "101", &manifest.ForgeLock{Minecraft: "1.19.2"}},
+		{"unavailable", "102", &manifest.ForgeLock{Minecraft: "1.20.1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestCurseForgeProvider(t)
+			_, err := provider.Resolve(context.Background(), &Request{
+				Dependency:   &pkgid.ID{Provider: "curseforge", Name: "jei", Version: tt.version},
+				Requirements: tt.requirements,
+			})
+			var mismatch *ErrCurseForgeFileMismatch
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("expected ErrCurseForgeFileMismatch, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCurseForgeProvider_ConvertURL(t *testing.T) {
+	provider := newTestCurseForgeProvider(t)
+
+	got, err := provider.ConvertURL(context.Background(), "https://www.curseforge.com/minecraft/mc-mods/jei/files/101")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "curseforge:jei@101" {
+		t.Errorf("expected curseforge:jei@101, got %s", got)
+	}
+}
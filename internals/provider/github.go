@@ -0,0 +1,514 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/minepkg/minepkg/internals/fabric"
+	"github.com/minepkg/minepkg/internals/github"
+	"github.com/minepkg/minepkg/internals/modrinth"
+	"github.com/minepkg/minepkg/internals/ownhttp"
+	"github.com/minepkg/minepkg/internals/pkgid"
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+// DefaultGitHubAssetGlob matches the release asset that gets installed
+const DefaultGitHubAssetGlob = "*.jar"
+
+var (
+	ErrInvalidGitHubRepo   = errors.New("github dependencies need to be in the github:owner/repo@tag format")
+	ErrNoMatchingRelease   = errors.New("no release matches the requested tag")
+	ErrNoMatchingJarAsset  = errors.New("release has no jar asset matching the asset glob")
+	ErrMultipleJarAssets   = errors.New("release has multiple jar assets, set a more specific asset glob")
+	ErrNoModrinthClient    = errors.New("no modrinth client to look up mod ids")
+	gitHubBuiltinModIDs    = map[string]bool{"minecraft": true, "java": true, "fabricloader": true, "fabric-loader": true, "quilt_loader": true}
+	gitHubFabricAPIModIDs  = map[string]bool{"fabric": true, "fabric-api": true}
+	gitHubIgnoredJarSuffix = []string{"-sources.jar", "-dev.jar", "-javadoc.jar", "-api.jar"}
+	fabricAPIModule        = regexp.MustCompile(`^fabric-.+(-v\d+|-api-base)$`)
+)
+
+type GitHubProvider struct {
+	Client *github.Client
+	// Modrinth is used to find the dependencies declared in the fabric.mod.json of a jar.
+	// They are skipped if this is nil
+	Modrinth *modrinth.Client
+	// HTTP is used to download release assets
+	HTTP *http.Client
+	// AssetGlob selects the jar asset of a release (eg. "*-fabric-*.jar")
+	AssetGlob string
+	// CacheDir stores the hash and fabric.mod.json of release assets, so they are only downloaded once.
+	// Nothing is cached if this is empty
+	CacheDir string
+}
+
+// gitHubAsset is what we need to know about a release asset. It is cached by url
+type gitHubAsset struct {
+	URL    string `json:"url"`
+	Sha256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+	// Manifest is the fabric.mod.json of the jar. nil if it is not a fabric mod
+	Manifest *fabric.Manifest `json:"manifest,omitempty"`
+	// Provided are the mod ids of the jar and its nested jars
+	Provided []string `json:"provided,omitempty"`
+}
+
+type gitHubResult struct {
+	name         string
+	release      *github.Release
+	assetURL     string
+	sha256       string
+	size         int64
+	environment  string
+	dependencies []*manifest.InterpretedDependency
+}
+
+func (g *gitHubResult) Lock() *manifest.DependencyLock {
+	return &manifest.DependencyLock{
+		Name:        g.name,
+		Version:     g.release.TagName,
+		VersionName: g.release.Name,
+		Type:        "mod",
+		URL:         g.assetURL,
+		Provider:    "github",
+		Sha256:      g.sha256,
+		Size:        g.size,
+		Environment: g.environment,
+	}
+}
+
+// Dependencies returns the dependencies declared in the fabric.mod.json of the jar
+func (g *gitHubResult) Dependencies() []*manifest.InterpretedDependency {
+	return g.dependencies
+}
+
+func NewGitHubProvider() *GitHubProvider {
+	client := ownhttp.New()
+	return &GitHubProvider{
+		Client:    github.NewClient(client),
+		Modrinth:  NewModrinthProvider().Client,
+		HTTP:      client,
+		AssetGlob: DefaultGitHubAssetGlob,
+	}
+}
+
+func (g *GitHubProvider) Name() string { return "github" }
+
+func (g *GitHubProvider) Resolve(ctx context.Context, request *Request) (Result, error) {
+	repo := request.Dependency.Name
+	if strings.Count(repo, "/") != 1 {
+		return nil, ErrInvalidGitHubRepo
+	}
+
+	releases, err := g.Client.ListReleases(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := pickGitHubRelease(releases, request.Dependency.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.newResult(ctx, request, release)
+}
+
+func (g *GitHubProvider) ResolveLatest(ctx context.Context, request *Request) (Result, error) {
+	latest := *request.Dependency
+	latest.Version = "latest"
+	return g.Resolve(ctx, &Request{
+		Dependency:   &latest,
+		Requirements: request.Requirements,
+		Root:         request.Root,
+	})
+}
+
+func (g *GitHubProvider) newResult(ctx context.Context, request *Request, release *github.Release) (*gitHubResult, error) {
+	platform := ""
+	if request.Requirements != nil {
+		platform = request.Requirements.PlatformName()
+	}
+
+	assetURL, err := g.pickAsset(release, platform)
+	if err != nil {
+		return nil, err
+	}
+
+	asset, err := g.asset(ctx, assetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &gitHubResult{
+		name:     path.Base(request.Dependency.Name),
+		release:  release,
+		assetURL: assetURL,
+		sha256:   asset.Sha256,
+		size:     asset.Size,
+	}
+
+	// not a fabric mod, so we can not know the dependencies
+	if asset.Manifest == nil {
+		return result, nil
+	}
+
+	result.dependencies = g.jarDependencies(ctx, asset.Manifest, asset.Provided, request.Requirements)
+	result.environment = asset.Manifest.Side()
+	return result, nil
+}
+
+// asset returns the hash and fabric.mod.json of the release asset. It is only downloaded if it is not cached yet
+func (g *GitHubProvider) asset(ctx context.Context, assetURL string) (*gitHubAsset, error) {
+	cachePath := ""
+	if g.CacheDir != "" {
+		cachePath = filepath.Join(g.CacheDir, fmt.Sprintf("%x.json", sha256.Sum256([]byte(assetURL))))
+		if cached, err := os.ReadFile(cachePath); err == nil {
+			asset := &gitHubAsset{}
+			if err := json.Unmarshal(cached, asset); err == nil && asset.URL == assetURL {
+				return asset, nil
+			}
+		}
+	}
+
+	// we need the jar to find its dependencies anyway, so we also hash it
+	content, err := g.download(ctx, assetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := &gitHubAsset{
+		URL:    assetURL,
+		Sha256: fmt.Sprintf("%x", sha256.Sum256(content)),
+		Size:   int64(len(content)),
+	}
+
+	jar, err := fabric.ReadJar(bytes.NewReader(content), int64(len(content)))
+	switch {
+	case err == fabric.ErrNoFabricManifest:
+	case err != nil:
+		return nil, fmt.Errorf("could not read %s: %w", assetURL, err)
+	default:
+		asset.Manifest = jar.Manifest
+		asset.Provided = jar.ProvidedIDs()
+	}
+
+	if cachePath != "" {
+		if err := writeGitHubAsset(cachePath, asset); err != nil {
+			log.Printf("Could not cache %s: %s", assetURL, err)
+		}
+	}
+	return asset, nil
+}
+
+// writeGitHubAsset stores the asset at p. It is written to a temporary file first, so other processes never read a partial file
+func writeGitHubAsset(p string, asset *gitHubAsset) error {
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return err
+	}
+	content, err := json.Marshal(asset)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".asset-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// pickAsset returns the download url of the jar asset matching the asset glob
+func (g *GitHubProvider) pickAsset(release *github.Release, platform string) (string, error) {
+	glob := g.AssetGlob
+	if glob == "" {
+		glob = DefaultGitHubAssetGlob
+	}
+
+	matches := make([]string, 0, 1)
+	for _, asset := range release.Assets {
+		matched, err := path.Match(glob, asset.Name)
+		if err != nil {
+			return "", fmt.Errorf("invalid asset glob %q: %w", glob, err)
+		}
+		if matched && isGitHubJarCandidate(asset.Name) {
+			matches = append(matches, asset.BrowserDownloadURL)
+		}
+	}
+
+	// prefer assets for our platform if there are multiple (eg. mod-fabric.jar & mod-forge.jar)
+	if len(matches) > 1 && platform != "" {
+		for _, loader := range compatibleLoaders(platform) {
+			forPlatform := make([]string, 0, 1)
+			for _, match := range matches {
+				name := strings.ToLower(path.Base(match))
+				// "neoforge" contains "forge"
+				if loader == manifest.PlatformForge {
+					name = strings.ReplaceAll(name, manifest.PlatformNeoForge, "")
+				}
+				if strings.Contains(name, loader) {
+					forPlatform = append(forPlatform, match)
+				}
+			}
+			if len(forPlatform) != 0 {
+				matches = forPlatform
+				break
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", ErrNoMatchingJarAsset
+	case 1:
+		return matches[0], nil
+	default:
+		return "", ErrMultipleJarAssets
+	}
+}
+
+func (g *GitHubProvider) download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := g.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", res.StatusCode, url)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+func (g *GitHubProvider) CanConvertURL(url string) bool {
+	return strings.HasPrefix(url, "https://github.com/") && strings.Contains(url, "/releases")
+}
+
+func (g *GitHubProvider) ConvertURL(ctx context.Context, rawURL string) (string, error) {
+	if !g.CanConvertURL(rawURL) {
+		return "", fmt.Errorf("url %s is not a github release url", rawURL)
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	// url looks like https://github.com/owner/repo/releases/tag/v1.0.0
+	// or https://github.com/owner/repo/releases/download/v1.0.0/mod.jar
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) < 3 || parts[2] != "releases" {
+		return "", fmt.Errorf("url %s is not a github release url", rawURL)
+	}
+	repo := parts[0] + "/" + parts[1]
+
+	if len(parts) >= 5 && (parts[3] == "tag" || parts[3] == "download") {
+		tag, err := url.PathUnescape(parts[4])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("github:%s@%s", repo, tag), nil
+	}
+
+	// release overview or /releases/latest
+	return fmt.Sprintf("github:%s@latest", repo), nil
+}
+
+// pickGitHubRelease returns the release with the given tag or the highest one matching the semver range
+func pickGitHubRelease(releases []github.Release, wanted string) (*github.Release, error) {
+	candidates := make([]*github.Release, 0, len(releases))
+	for i := range releases {
+		if !releases[i].Draft {
+			candidates = append(candidates, &releases[i])
+		}
+	}
+
+	if wanted == "" || wanted == "*" || wanted == "latest" {
+		// newest stable release
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return candidates[a].PublishedAt.After(candidates[b].PublishedAt)
+		})
+		for _, release := range candidates {
+			if !release.Prerelease {
+				return release, nil
+			}
+		}
+		return nil, ErrNoMatchingRelease
+	}
+
+	for _, release := range candidates {
+		if release.TagName == wanted {
+			return release, nil
+		}
+	}
+
+	constraint, err := semver.NewConstraint(wanted)
+	if err != nil {
+		return nil, ErrNoMatchingRelease
+	}
+
+	var best *github.Release
+	var bestVersion *semver.Version
+	for _, release := range candidates {
+		// semver also accepts tags with a "v" prefix
+		version, err := semver.NewVersion(release.TagName)
+		if err != nil || !constraint.Check(version) {
+			continue
+		}
+		if bestVersion == nil || version.GreaterThan(bestVersion) {
+			best = release
+			bestVersion = version
+		}
+	}
+
+	if best == nil {
+		return nil, ErrNoMatchingRelease
+	}
+
+	return best, nil
+}
+
+// jarDependencies maps the "depends" of a fabric.mod.json to dependencies (fabric api is installed from minepkg).
+// mod ids have no registry, so they are looked up on modrinth. Mods that can not be found there
+// in a matching version are skipped, the mod requirements are checked again before launching.
+// `provided` are the mod ids of the jar and its nested jars, they do not need to be installed
+func (g *GitHubProvider) jarDependencies(ctx context.Context, fabricManifest *fabric.Manifest, provided []string, requirements manifest.PlatformLock) []*manifest.InterpretedDependency {
+	// mods that are included in the jar do not need to be installed
+	included := make(map[string]bool)
+	for _, id := range provided {
+		included[id] = true
+	}
+
+	ids := make([]string, 0, len(fabricManifest.Depends))
+	for id := range fabricManifest.Depends {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	seenFabricAPI := false
+	dependencies := make([]*manifest.InterpretedDependency, 0, len(ids))
+	for _, id := range ids {
+		if included[id] || gitHubBuiltinModIDs[id] {
+			continue
+		}
+
+		// fabric api modules (eg. fabric-networking-api-v1) are part of fabric api
+		if gitHubFabricAPIModIDs[id] || fabricAPIModule.MatchString(id) {
+			if seenFabricAPI {
+				continue
+			}
+			seenFabricAPI = true
+			dependencyID := &pkgid.ID{Provider: "minepkg", Name: "fabric", Version: "latest"}
+			dependencies = append(dependencies, &manifest.InterpretedDependency{
+				Provider: dependencyID.Provider,
+				Name:     dependencyID.Name,
+				Source:   dependencyID.Version,
+				ID:       dependencyID,
+			})
+			continue
+		}
+
+		dependency, err := g.modrinthDependency(ctx, id, fabricManifest.Depends[id], requirements)
+		if err != nil {
+			log.Printf("Skipping dependency %s of %s: %s", id, fabricManifest.ID, err)
+			continue
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	return dependencies
+}
+
+// modrinthDependency returns the modrinth project with the mod id as slug. The newest compatible version
+// has to match the predicates of the fabric.mod.json, otherwise the newest matching version is pinned
+func (g *GitHubProvider) modrinthDependency(ctx context.Context, id string, predicates []string, requirements manifest.PlatformLock) (*manifest.InterpretedDependency, error) {
+	if g.Modrinth == nil {
+		return nil, ErrNoModrinthClient
+	}
+
+	var versions []modrinth.Version
+	if requirements == nil {
+		var err error
+		if versions, err = g.Modrinth.ListProjectVersion(ctx, id, nil); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, loader := range compatibleLoaders(requirements.PlatformName()) {
+			query := &modrinth.ListProjectVersionQuery{
+				Loaders:      []string{loader},
+				GameVersions: []string{requirements.MinecraftVersion()},
+			}
+			found, err := g.Modrinth.ListProjectVersion(ctx, id, query)
+			if err != nil {
+				return nil, err
+			}
+			if len(found) != 0 {
+				versions = found
+				break
+			}
+		}
+	}
+	if len(versions) == 0 {
+		return nil, ErrCouldNotFindLatestVersion
+	}
+
+	version := "latest"
+	if !fabric.VersionMatches(predicates, versions[0].VersionNumber) {
+		version = ""
+		for _, candidate := range versions[1:] {
+			if fabric.VersionMatches(predicates, candidate.VersionNumber) {
+				version = candidate.ID
+				break
+			}
+		}
+		if version == "" {
+			return nil, fmt.Errorf("no version on modrinth matches %s", strings.Join(predicates, " || "))
+		}
+	}
+
+	dependencyID := &pkgid.ID{Provider: "modrinth", Name: id, Version: version}
+	return &manifest.InterpretedDependency{
+		Provider: dependencyID.Provider,
+		Name:     id,
+		Source:   dependencyID.Version,
+		ID:       dependencyID,
+	}, nil
+}
+
+func isGitHubJarCandidate(name string) bool {
+	if !strings.HasSuffix(name, ".jar") {
+		return false
+	}
+	for _, suffix := range gitHubIgnoredJarSuffix {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minepkg/minepkg/internals/github"
+	"github.com/minepkg/minepkg/internals/modrinth"
+	"github.com/minepkg/minepkg/internals/pkgid"
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+const testFabricModJSON = `{
+  "schemaVersion": 1,
+  "id": "test-mod",
+  "version": "1.1.0",
+  "depends": {
+    "fabricloader": ">=0.14.0",
+    "minecraft": "1.20.x",
+    "fabric-networking-api-v1": "*",
+    "fabric-api": "*",
+    "cloth-config": "<13.0.0",
+    "modmenu": "*",
+    "not-on-modrinth": "*"
+  }
+}`
+
+func testJar(t *testing.T) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	file, err := archive.Create("fabric.mod.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte(testFabricModJSON))
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func newTestGitHubProvider(t *testing.T) *GitHubProvider {
+	jar := testJar(t)
+	var server *httptest.Server
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/test-mod/releases", func(w http.ResponseWriter, r *http.Request) {
+		release := func(tag string, published time.Time, prerelease bool, assets ...string) map[string]interface{} {
+			releaseAssets := make([]map[string]interface{}, 0, len(assets))
+			for _, name := range assets {
+				releaseAssets = append(releaseAssets, map[string]interface{}{
+					"name":                 name,
+					"browser_download_url": server.URL + "/download/" + name,
+				})
+			}
+			return map[string]interface{}{
+				"tag_name":     tag,
+				"published_at": published,
+				"prerelease":   prerelease,
+				"assets":       releaseAssets,
+			}
+		}
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			release("v2.0.0-beta.1", time.Unix(300, 0), true, "test-mod-2.0.0-beta.1.jar"),
+			release("v1.1.0", time.Unix(200, 0), false, "test-mod-1.1.0-fabric.jar", "test-mod-1.1.0-forge.jar", "test-mod-1.1.0-sources.jar"),
+			release("v1.0.0", time.Unix(100, 0), false, "test-mod-1.0.0.jar"),
+		})
+	})
+	mux.HandleFunc("/download/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(jar)
+	})
+	// mod ids are looked up on modrinth
+	modrinthVersions := map[string][]modrinth.Version{
+		"cloth-config": {{ID: "CLOTH131", VersionNumber: "13.1.0+fabric"}, {ID: "CLOTH120", VersionNumber: "12.0.0+fabric"}},
+		"modmenu":      {{ID: "MODMENU7", VersionNumber: "7.2.2"}},
+	}
+	mux.HandleFunc("/v2/project/{id}/version", func(w http.ResponseWriter, r *http.Request) {
+		versions, ok := modrinthVersions[r.PathValue("id")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(versions)
+	})
+
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(server.Client())
+	client.BaseURL = server.URL
+	modrinthClient := modrinth.New(server.Client())
+	if err := modrinthClient.SetBaseURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	return &GitHubProvider{Client: client, Modrinth: modrinthClient, HTTP: server.Client(), AssetGlob: DefaultGitHubAssetGlob}
+}
+
+func TestGitHubProvider_Resolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		wantTag   string
+		wantAsset string
+	}{
+		{"latest stable", "latest", "v1.1.0", "test-mod-1.1.0-fabric.jar"},
+		{"semver range", "~1.0.0", "v1.0.0", "test-mod-1.0.0.jar"},
+		{"exact tag", "v2.0.0-beta.1", "v2.0.0-beta.1", "test-mod-2.0.0-beta.1.jar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestGitHubProvider(t)
+			res, err := provider.Resolve(context.Background(), &Request{
+				Dependency:   pkgid.Parse("github:owner/test-mod@" + tt.version),
+				Requirements: &manifest.FabricLock{Minecraft: "1.20.1"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lock := res.Lock()
+			if lock.Version != tt.wantTag || path.Base(lock.URL) != tt.wantAsset || lock.Sha256 == "" {
+				t.Errorf("got %s (%s), want %s (%s)", lock.Version, lock.URL, tt.wantTag, tt.wantAsset)
+			}
+
+			names := make([]string, 0, len(res.Dependencies()))
+			for _, dependency := range res.Dependencies() {
+				names = append(names, dependency.ID.Provider+":"+dependency.Name+"@"+dependency.Source)
+			}
+			// not-on-modrinth is skipped, cloth-config is pinned to a version matching the range
+			if strings.Join(names, " ") != "modrinth:cloth-config@CLOTH120 minepkg:fabric@latest modrinth:modmenu@latest" {
+				t.Errorf("unexpected dependencies %v", names)
+			}
+		})
+	}
+}
+
+func TestGitHubProvider_Resolve_cachesAssets(t *testing.T) {
+	provider := newTestGitHubProvider(t)
+	provider.CacheDir = t.TempDir()
+	downloads := 0
+	provider.HTTP = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		downloads++
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+
+	request := &Request{
+		Dependency:   pkgid.Parse("github:owner/test-mod@v1.0.0"),
+		Requirements: &manifest.FabricLock{Minecraft: "1.20.1"},
+	}
+	first, err := provider.Resolve(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := provider.Resolve(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if downloads != 1 {
+		t.Errorf("expected the asset to be downloaded once, got %d downloads", downloads)
+	}
+	if first.Lock().Sha256 != second.Lock().Sha256 || len(first.Dependencies()) != len(second.Dependencies()) {
+		t.Errorf("expected the cached result to be the same, got %+v and %+v", first.Lock(), second.Lock())
+	}
+}
+
+// roundTripFunc is a http.RoundTripper that calls the function
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestGitHubProvider_ConvertURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/owner/repo/releases/tag/v1.0.0", "github:owner/repo@v1.0.0"},
+		{"https://github.com/owner/repo/releases/download/v1.0.0/mod.jar", "github:owner/repo@v1.0.0"},
+		{"https://github.com/owner/repo/releases/latest", "github:owner/repo@latest"},
+	}
+	provider := NewGitHubProvider()
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := provider.ConvertURL(context.Background(), tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ConvertURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
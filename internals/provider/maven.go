@@ -0,0 +1,396 @@
+package provider
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/minepkg/minepkg/internals/ownhttp"
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+// DefaultMavenRepositories are searched after the repositories of the manifest and the global config
+var DefaultMavenRepositories = []string{
+	"https://maven.fabricmc.net/",
+	"https://api.modrinth.com/maven/",
+	"https://jitpack.io/",
+	"https://repo1.maven.org/maven2/",
+}
+
+var (
+	ErrInvalidMavenCoordinate = errors.New("maven dependencies need to be in the group:artifact@version format")
+	ErrMavenArtifactNotFound  = errors.New("artifact not found in any maven repository")
+	ErrMavenNoChecksum        = errors.New("maven repository does not provide a .sha1 or .sha256 checksum")
+)
+
+// ErrMavenChecksumMismatch is returned if the .sha1 and .sha256 sidecar files are not valid
+type ErrMavenChecksumMismatch struct {
+	URL  string
+	Algo string
+	Sum  string
+}
+
+func (e *ErrMavenChecksumMismatch) Error() string {
+	return fmt.Sprintf("%s of %s is not a valid checksum: %q", e.Algo, e.URL, e.Sum)
+}
+
+type MavenProvider struct {
+	Client *http.Client
+	// Repositories are searched in order for artifacts (after the ones requested by the manifest)
+	Repositories []string
+}
+
+type mavenResult struct {
+	name    string
+	version string
+	url     string
+	sha1    string
+	sha256  string
+}
+
+func (m *mavenResult) Lock() *manifest.DependencyLock {
+	return &manifest.DependencyLock{
+		Name:     m.name,
+		Version:  m.version,
+		Type:     "mod",
+		URL:      m.url,
+		Provider: "maven",
+		Sha1:     m.sha1,
+		Sha256:   m.sha256,
+	}
+}
+
+func (m *mavenResult) Dependencies() []*manifest.InterpretedDependency {
+	// pom dependencies are compile dependencies and usually already included (jar-in-jar)
+	return nil
+}
+
+// mavenMetadata is the relevant subset of a maven-metadata.xml file
+type mavenMetadata struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Versioning struct {
+		Latest   string   `xml:"latest"`
+		Release  string   `xml:"release"`
+		Versions []string `xml:"versions>version"`
+	} `xml:"versioning"`
+}
+
+func NewMavenProvider() *MavenProvider {
+	return &MavenProvider{
+		Client:       ownhttp.New(),
+		Repositories: DefaultMavenRepositories,
+	}
+}
+
+func (m *MavenProvider) Name() string { return "maven" }
+
+func (m *MavenProvider) Resolve(ctx context.Context, request *Request) (Result, error) {
+	group, artifact, version, err := parseMavenCoordinate(request.Dependency.Name, request.Dependency.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, repository := range m.repositories(request) {
+		resolved, err := m.resolveIn(ctx, repository, group, artifact, version)
+		if err == ErrMavenArtifactNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", repository, err)
+		}
+
+		// the lock entry is keyed like the manifest entry, not by the artifact id
+		resolved.name = request.Dependency.Name
+		return resolved, nil
+	}
+
+	return nil, ErrMavenArtifactNotFound
+}
+
+func (m *MavenProvider) ResolveLatest(ctx context.Context, request *Request) (Result, error) {
+	latest := *request.Dependency
+	latest.Version = "latest"
+	return m.Resolve(ctx, &Request{
+		Dependency:        &latest,
+		Requirements:      request.Requirements,
+		Root:              request.Root,
+		MavenRepositories: request.MavenRepositories,
+	})
+}
+
+// repositories returns the repositories of the request followed by the configured ones
+func (m *MavenProvider) repositories(request *Request) []string {
+	repositories := make([]string, 0, len(request.MavenRepositories)+len(m.Repositories))
+	seen := make(map[string]bool)
+	for _, repository := range append(append([]string{}, request.MavenRepositories...), m.Repositories...) {
+		repository = strings.TrimSuffix(repository, "/") + "/"
+		if seen[repository] {
+			continue
+		}
+		seen[repository] = true
+		repositories = append(repositories, repository)
+	}
+	return repositories
+}
+
+// resolveIn tries to resolve the artifact in a single repository.
+// returns ErrMavenArtifactNotFound if the repository does not contain a matching version
+func (m *MavenProvider) resolveIn(ctx context.Context, repository, group, artifact, version string) (*mavenResult, error) {
+	base, err := url.JoinPath(repository, strings.ReplaceAll(group, ".", "/"), artifact)
+	if err != nil {
+		return nil, err
+	}
+
+	// exact versions are fetched directly (some repositories do not have up to date metadata)
+	if isMavenRange(version) {
+		metadata, err := m.fetchMetadata(ctx, base+"/maven-metadata.xml")
+		if err != nil {
+			return nil, err
+		}
+		version, err = pickMavenVersion(metadata, version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	jarURL := fmt.Sprintf("%s/%s/%s-%s.jar", base, version, artifact, version)
+
+	sha1, err := m.fetchChecksum(ctx, jarURL+".sha1", 40)
+	if err != nil && err != ErrMavenArtifactNotFound {
+		return nil, err
+	}
+	sha256, err := m.fetchChecksum(ctx, jarURL+".sha256", 64)
+	if err != nil && err != ErrMavenArtifactNotFound {
+		return nil, err
+	}
+
+	if sha1 == "" && sha256 == "" {
+		// make sure that the jar exists at all, before complaining about missing checksums
+		if err := m.head(ctx, jarURL); err != nil {
+			return nil, err
+		}
+		return nil, ErrMavenNoChecksum
+	}
+
+	return &mavenResult{version: version, url: jarURL, sha1: sha1, sha256: sha256}, nil
+}
+
+func (m *MavenProvider) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := m.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, ErrMavenArtifactNotFound
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected status code %d from %s", res.StatusCode, url)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+func (m *MavenProvider) head(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := m.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return ErrMavenArtifactNotFound
+	case res.StatusCode != http.StatusOK:
+		return fmt.Errorf("unexpected status code %d from %s", res.StatusCode, url)
+	}
+
+	return nil
+}
+
+func (m *MavenProvider) fetchMetadata(ctx context.Context, url string) (*mavenMetadata, error) {
+	body, err := m.get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata mavenMetadata
+	if err := xml.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid maven-metadata.xml: %w", err)
+	}
+
+	return &metadata, nil
+}
+
+// fetchChecksum fetches a checksum sidecar file and validates its format
+func (m *MavenProvider) fetchChecksum(ctx context.Context, url string, length int) (string, error) {
+	body, err := m.get(ctx, url)
+	if err != nil {
+		return "", err
+	}
+
+	// some repositories append the filename after the checksum
+	fields := strings.Fields(string(body))
+	if len(fields) == 0 {
+		return "", &ErrMavenChecksumMismatch{url, fmt.Sprintf("%d char checksum", length), ""}
+	}
+
+	sum := strings.ToLower(fields[0])
+	if len(sum) != length || strings.Trim(sum, "0123456789abcdef") != "" {
+		return "", &ErrMavenChecksumMismatch{url, fmt.Sprintf("%d char checksum", length), sum}
+	}
+
+	return sum, nil
+}
+
+// parseMavenCoordinate splits a `group:artifact` name and version.
+// `maven:group:artifact` (without a version) is also accepted and will resolve the latest version
+func parseMavenCoordinate(name string, version string) (group, artifact, parsedVersion string, err error) {
+	if !strings.Contains(name, ":") && strings.Contains(version, ":") {
+		name = version
+		version = "latest"
+	}
+
+	parts := strings.Split(name, ":")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", ErrInvalidMavenCoordinate
+	}
+
+	if version == "" || version == "*" {
+		version = "latest"
+	}
+
+	return parts[0], parts[1], version, nil
+}
+
+// isMavenRange returns true if the version is not an exact version
+func isMavenRange(version string) bool {
+	if version == "latest" {
+		return true
+	}
+	if _, err := semver.StrictNewVersion(version); err == nil {
+		return false
+	}
+	return strings.ContainsAny(version, "[]()<>=^~*, |")
+}
+
+// pickMavenVersion returns the best version of the metadata that matches the wanted version or range
+func pickMavenVersion(metadata *mavenMetadata, wanted string) (string, error) {
+	versions := metadata.Versioning.Versions
+
+	if wanted == "latest" {
+		switch {
+		case metadata.Versioning.Release != "":
+			return metadata.Versioning.Release, nil
+		case metadata.Versioning.Latest != "":
+			return metadata.Versioning.Latest, nil
+		case len(versions) != 0:
+			return versions[len(versions)-1], nil
+		}
+		return "", ErrMavenArtifactNotFound
+	}
+
+	for _, version := range versions {
+		if version == wanted {
+			return version, nil
+		}
+	}
+
+	constraint, err := semver.NewConstraint(mavenRangeToSemver(wanted))
+	if err != nil {
+		// not a range and not found in this repository
+		return "", ErrMavenArtifactNotFound
+	}
+
+	var best *semver.Version
+	for _, raw := range versions {
+		version, err := semver.NewVersion(raw)
+		if err != nil {
+			continue
+		}
+		if constraint.Check(version) && (best == nil || version.GreaterThan(best)) {
+			best = version
+		}
+	}
+
+	if best == nil {
+		return "", ErrMavenArtifactNotFound
+	}
+
+	return best.Original(), nil
+}
+
+// mavenRangeToSemver converts maven ranges (eg. `[1.0,2.0)`) to semver constraints (eg. `>=1.0, <2.0`).
+// other strings are returned unchanged
+func mavenRangeToSemver(versionRange string) string {
+	if !strings.HasPrefix(versionRange, "[") && !strings.HasPrefix(versionRange, "(") {
+		return versionRange
+	}
+
+	// multiple ranges like `[1.0,1.2),[1.3,)` are combined using "or"
+	sets := make([]string, 0, 1)
+	for _, set := range strings.SplitAfter(versionRange, ")") {
+		for _, part := range strings.SplitAfter(set, "]") {
+			part = strings.Trim(strings.TrimSpace(part), ",")
+			if part == "" {
+				continue
+			}
+			sets = append(sets, convertMavenRangeSet(part))
+		}
+	}
+
+	return strings.Join(sets, " || ")
+}
+
+func convertMavenRangeSet(set string) string {
+	if len(set) < 2 {
+		return set
+	}
+	open, close := set[0], set[len(set)-1]
+	inner := set[1 : len(set)-1]
+
+	bounds := strings.SplitN(inner, ",", 2)
+	if len(bounds) == 1 {
+		// [1.0] means exactly 1.0
+		return "=" + strings.TrimSpace(bounds[0])
+	}
+
+	constraints := make([]string, 0, 2)
+	if lower := strings.TrimSpace(bounds[0]); lower != "" {
+		if open == '[' {
+			constraints = append(constraints, ">="+lower)
+		} else {
+			constraints = append(constraints, ">"+lower)
+		}
+	}
+	if upper := strings.TrimSpace(bounds[1]); upper != "" {
+		if close == ']' {
+			constraints = append(constraints, "<="+upper)
+		} else {
+			constraints = append(constraints, "<"+upper)
+		}
+	}
+
+	if len(constraints) == 0 {
+		return "*"
+	}
+	return strings.Join(constraints, ", ")
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/minepkg/minepkg/internals/pkgid"
+)
+
+const testMavenMetadata = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>net.example</groupId>
+  <artifactId>lib</artifactId>
+  <versioning>
+    <latest>2.0.0-beta.1</latest>
+    <release>1.5.0</release>
+    <versions>
+      <version>1.0.0</version>
+      <version>1.5.0</version>
+      <version>2.0.0-beta.1</version>
+    </versions>
+  </versioning>
+</metadata>`
+
+func newTestMavenProvider(t *testing.T) *MavenProvider {
+	files := map[string]string{
+		"/repo/net/example/lib/maven-metadata.xml":                     testMavenMetadata,
+		"/repo/net/example/lib/1.0.0/lib-1.0.0.jar.sha1":               "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		"/repo/net/example/lib/1.5.0/lib-1.5.0.jar.sha1":               "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb  lib-1.5.0.jar",
+		"/repo/net/example/lib/1.5.0/lib-1.5.0.jar.sha256":             "cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc",
+		"/repo/net/example/lib/2.0.0-beta.1/lib-2.0.0-beta.1.jar.sha1": "not a checksum",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	return &MavenProvider{
+		Client:       server.Client(),
+		Repositories: []string{server.URL + "/empty", server.URL + "/repo"},
+	}
+}
+
+func TestMavenProvider_Resolve(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		wantVersion string
+		wantSha256  string
+		wantErr     bool
+	}{
+		{"exact version", "1.0.0", "1.0.0", "", false},
+		{"latest release", "latest", "1.5.0", "cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc", false},
+		{"semver range", "^1.0.0", "1.5.0", "cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc", false},
+		{"maven range", "[1.0.0,1.5.0)", "1.0.0", "", false},
+		{"invalid checksum", "2.0.0-beta.1", "", "", true},
+		{"missing version", "3.0.0", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestMavenProvider(t)
+			res, err := provider.Resolve(context.Background(), &Request{
+				Dependency: pkgid.Parse("maven:net.example:lib@" + tt.version),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			lock := res.Lock()
+			if lock.Name != "net.example:lib" {
+				t.Errorf("expected the lock to keep the requested name, got %q", lock.Name)
+			}
+			if lock.Version != tt.wantVersion || lock.Sha256 != tt.wantSha256 || lock.Sha1 == "" {
+				t.Errorf("got %s (sha1 %q, sha256 %q), want %s (sha256 %q)", lock.Version, lock.Sha1, lock.Sha256, tt.wantVersion, tt.wantSha256)
+			}
+		})
+	}
+}
+
+func TestMavenRangeToSemver(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"^1.0.0", "^1.0.0"},
+		{"[1.0,2.0)", ">=1.0, <2.0"},
+		{"(1.0,]", ">1.0"},
+		{"[1.2]", "=1.2"},
+		{"[1.0,1.2),[1.3,)", ">=1.0, <1.2 || >=1.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := mavenRangeToSemver(tt.in); got != tt.want {
+				t.Errorf("mavenRangeToSemver() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 
@@ -33,22 +34,39 @@ func (m *minepkgResult) Dependencies() []*manifest.InterpretedDependency {
 	return m.InterpretedDependencies()
 }
 
+// Incompatibilities returns the incompatibilities declared in the manifest of this release
+func (m *minepkgResult) Incompatibilities() []*manifest.InterpretedDependency {
+	return m.InterpretedIncompatibilities()
+}
+
 func (m *MinepkgProvider) Name() string { return "minepkg" }
 
 func (m *MinepkgProvider) Resolve(ctx context.Context, request *Request) (Result, error) {
-	reqs := &api.ReleasesQuery{
-		Name:         request.Dependency.Name,
-		VersionRange: request.Dependency.Version,
-		Minecraft:    request.Requirements.MinecraftVersion(),
-		Platform:     request.Requirements.PlatformName(),
-	}
+	var firstErr error
+	for _, platform := range compatibleLoaders(request.Requirements.PlatformName()) {
+		reqs := &api.ReleasesQuery{
+			Name:         request.Dependency.Name,
+			VersionRange: request.Dependency.Version,
+			Minecraft:    request.Requirements.MinecraftVersion(),
+			Platform:     platform,
+		}
 
-	release, err := m.Client.ReleasesQuery(ctx, reqs)
-	if err != nil {
-		return nil, err
+		release, err := m.Client.ReleasesQuery(ctx, reqs)
+		if err == nil {
+			return &minepkgResult{release}, nil
+		}
+
+		// only try the next compatible platform if there was no matching release
+		var noResult *api.ErrNoQueryResult
+		if !errors.As(err, &noResult) {
+			return nil, err
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
 	}
 
-	return &minepkgResult{release}, nil
+	return nil, firstErr
 }
 
 func (m *MinepkgProvider) ResolveLatest(ctx context.Context, request *Request) (Result, error) {
@@ -7,9 +7,11 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/minepkg/minepkg/internals/modrinth"
 	"github.com/minepkg/minepkg/internals/ownhttp"
+	"github.com/minepkg/minepkg/internals/pkgid"
 	"github.com/minepkg/minepkg/pkg/manifest"
 	"golang.org/x/time/rate"
 )
@@ -20,14 +22,30 @@ var (
 	ErrVersionHasNoFiles         = errors.New("version has no files")
 )
 
+// ErrModrinthVersionMismatch is returned if a version that was requested by id or hash does not work with the requirements
+type ErrModrinthVersionMismatch struct {
+	Version *modrinth.Version
+	Reason  string
+}
+
+func (e *ErrModrinthVersionMismatch) Error() string {
+	return fmt.Sprintf("modrinth version %s (%s) %s", e.Version.ID, e.Version.VersionNumber, e.Reason)
+}
+
 type ModrinthProvider struct {
 	Client *modrinth.Client
+
+	// projects caches project id -> project lookups
+	projects sync.Map
 }
 
 type modrinthResult struct {
-	name    string
-	version *modrinth.Version
-	file    *modrinth.File
+	name              string
+	version           *modrinth.Version
+	file              *modrinth.File
+	environment       string
+	dependencies      []*manifest.InterpretedDependency
+	incompatibilities []*manifest.InterpretedDependency
 }
 
 func (m *modrinthResult) Lock() *manifest.DependencyLock {
@@ -38,14 +56,23 @@ func (m *modrinthResult) Lock() *manifest.DependencyLock {
 		Type:        "mod",
 		URL:         m.file.URL,
 		Provider:    "modrinth",
+		Sha1:        m.file.Hashes.Sha1,
 		Sha512:      m.file.Hashes.Sha512,
+		Size:        int64(m.file.Size),
+		Environment: m.environment,
 	}
 
 	return lock
 }
 
+// Dependencies returns the "required" dependencies of this version
 func (m *modrinthResult) Dependencies() []*manifest.InterpretedDependency {
-	return nil
+	return m.dependencies
+}
+
+// Incompatibilities returns the "incompatible" dependencies of this version
+func (m *modrinthResult) Incompatibilities() []*manifest.InterpretedDependency {
+	return m.incompatibilities
 }
 
 func NewModrinthProvider() *ModrinthProvider {
@@ -72,6 +99,9 @@ func (m *ModrinthProvider) Resolve(ctx context.Context, request *Request) (Resul
 		wantedVersion, err = m.resolveLatest(ctx, request)
 	} else {
 		wantedVersion, err = m.resolveById(ctx, request.Dependency.Version)
+		if err == nil {
+			err = checkModrinthVersion(wantedVersion, request.Requirements)
+		}
 	}
 	if err != nil {
 		return nil, err
@@ -82,11 +112,7 @@ func (m *ModrinthProvider) Resolve(ctx context.Context, request *Request) (Resul
 		return nil, ErrVersionHasNoFiles
 	}
 
-	return &modrinthResult{
-		name:    request.Dependency.Name,
-		version: wantedVersion,
-		file:    fileFromVersion(wantedVersion),
-	}, nil
+	return m.newResult(ctx, request.Dependency.Name, wantedVersion)
 }
 
 func (m *ModrinthProvider) ResolveLatest(ctx context.Context, request *Request) (Result, error) {
@@ -99,13 +125,116 @@ func (m *ModrinthProvider) ResolveLatest(ctx context.Context, request *Request)
 		return nil, ErrVersionHasNoFiles
 	}
 
-	return &modrinthResult{
-		name:    request.Dependency.Name,
-		version: version,
-		file:    fileFromVersion(version),
+	return m.newResult(ctx, request.Dependency.Name, version)
+}
+
+func (m *ModrinthProvider) newResult(ctx context.Context, name string, version *modrinth.Version) (*modrinthResult, error) {
+	project, err := m.project(ctx, version.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &modrinthResult{
+		name:        name,
+		version:     version,
+		file:        fileFromVersion(version),
+		environment: modrinthEnvironment(project),
+	}
+
+	for _, dependency := range version.Dependencies {
+		switch dependency.DependencyType {
+		case "required", "incompatible":
+		default:
+			// optional and embedded dependencies are not installed
+			continue
+		}
+
+		interpreted, err := m.interpretDependency(ctx, &dependency)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve dependency of %s: %w", name, err)
+		}
+
+		if dependency.DependencyType == "required" {
+			result.dependencies = append(result.dependencies, interpreted)
+		} else {
+			result.incompatibilities = append(result.incompatibilities, interpreted)
+		}
+	}
+
+	return result, nil
+}
+
+// interpretDependency maps a modrinth dependency to a modrinth:slug@version dependency
+func (m *ModrinthProvider) interpretDependency(ctx context.Context, dependency *modrinth.Dependency) (*manifest.InterpretedDependency, error) {
+	projectID := dependency.ProjectID
+	version := "latest"
+
+	if dependency.VersionID != "" {
+		version = dependency.VersionID
+		// some dependencies only reference a version
+		if projectID == "" {
+			v, err := m.Client.GetVersion(ctx, dependency.VersionID)
+			if err != nil {
+				return nil, err
+			}
+			projectID = v.ProjectID
+		}
+	}
+
+	if projectID == "" {
+		return nil, fmt.Errorf("dependency has neither project nor version id")
+	}
+
+	slug, err := m.projectSlug(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	id := &pkgid.ID{Provider: "modrinth", Name: slug, Version: version}
+	return &manifest.InterpretedDependency{
+		Provider: id.Provider,
+		Name:     slug,
+		Source:   version,
+		ID:       id,
 	}, nil
 }
 
+// projectSlug returns the slug for the given project id
+func (m *ModrinthProvider) projectSlug(ctx context.Context, projectID string) (string, error) {
+	project, err := m.project(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+	return project.Slug, nil
+}
+
+// project returns the (cached) project for the given project id
+func (m *ModrinthProvider) project(ctx context.Context, projectID string) (*modrinth.Project, error) {
+	if project, ok := m.projects.Load(projectID); ok {
+		return project.(*modrinth.Project), nil
+	}
+
+	project, err := m.Client.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	m.projects.Store(projectID, project)
+	return project, nil
+}
+
+// modrinthEnvironment returns the side the project is needed on or "" if it is needed on both
+func modrinthEnvironment(project *modrinth.Project) string {
+	switch {
+	case project.ServerSide == "unsupported" && project.ClientSide != "unsupported":
+		return manifest.EnvironmentClient
+	case project.ClientSide == "unsupported" && project.ServerSide != "unsupported":
+		return manifest.EnvironmentServer
+	default:
+		return ""
+	}
+}
+
 func (m *ModrinthProvider) resolveById(ctx context.Context, id string) (*modrinth.Version, error) {
 	switch len(id) {
 	case 8:
@@ -122,22 +251,50 @@ func (m *ModrinthProvider) resolveById(ctx context.Context, id string) (*modrint
 }
 
 func (m *ModrinthProvider) resolveLatest(ctx context.Context, request *Request) (*modrinth.Version, error) {
-	query := &modrinth.ListProjectVersionQuery{
-		Loaders:      []string{request.Requirements.PlatformName()},
-		GameVersions: []string{request.Requirements.MinecraftVersion()},
+	for _, loader := range compatibleLoaders(request.Requirements.PlatformName()) {
+		query := &modrinth.ListProjectVersionQuery{
+			Loaders:      []string{loader},
+			GameVersions: []string{request.Requirements.MinecraftVersion()},
+		}
+
+		versions, err := m.Client.ListProjectVersion(ctx, request.Dependency.Name, query)
+		if err != nil {
+			return nil, err
+		}
+
+		// grab the first version (not sure if this is good)
+		if len(versions) != 0 {
+			return &versions[0], nil
+		}
 	}
 
-	versions, err := m.Client.ListProjectVersion(ctx, request.Dependency.Name, query)
-	if err != nil {
-		return nil, err
+	return nil, ErrCouldNotFindLatestVersion
+}
+
+// checkModrinthVersion applies the filters of resolveLatest to a version that was requested by id or hash
+func checkModrinthVersion(version *modrinth.Version, requirements manifest.PlatformLock) error {
+	if requirements == nil {
+		return nil
 	}
 
-	if len(versions) == 0 {
-		return nil, ErrCouldNotFindLatestVersion
+	if !containsString(version.GameVersions, requirements.MinecraftVersion()) {
+		return &ErrModrinthVersionMismatch{version, fmt.Sprintf(
+			"does not support Minecraft %s (it supports %s)",
+			requirements.MinecraftVersion(),
+			strings.Join(version.GameVersions, ", "),
+		)}
 	}
 
-	// grab the first version (not sure if this is good)
-	return &versions[0], nil
+	for _, loader := range compatibleLoaders(requirements.PlatformName()) {
+		if containsString(version.Loaders, loader) {
+			return nil
+		}
+	}
+	return &ErrModrinthVersionMismatch{version, fmt.Sprintf(
+		"does not support %s (it supports %s)",
+		requirements.PlatformName(),
+		strings.Join(version.Loaders, ", "),
+	)}
 }
 
 func (m *ModrinthProvider) Fetch(ctx context.Context, toFetch Result) (io.Reader, int, error) {
@@ -2,14 +2,100 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/minepkg/minepkg/internals/modrinth"
 	"github.com/minepkg/minepkg/internals/pkgid"
+	"github.com/minepkg/minepkg/pkg/manifest"
 )
 
+// newModrinthTestServer serves a tiny subset of the modrinth api
+func newModrinthTestServer(t *testing.T) *httptest.Server {
+	projects := map[string]modrinth.Project{
+		"SODIUMPR": {ID: "SODIUMPR", Slug: "sodium"},
+		"FAPIPROJ": {ID: "FAPIPROJ", Slug: "fabric-api"},
+		"OPTPROJ1": {ID: "OPTPROJ1", Slug: "some-addon"},
+		"OPTIFINE": {ID: "OPTIFINE", Slug: "optifine"},
+		"LIBPROJ1": {ID: "LIBPROJ1", Slug: "some-lib"},
+	}
+
+	file := modrinth.File{URL: "https://example.com/sodium.jar", Primary: true, Size: 3}
+	file.Hashes.Sha1 = "abc"
+	versions := map[string]modrinth.Version{
+		"SODIUM01": {
+			ID:            "SODIUM01",
+			ProjectID:     "SODIUMPR",
+			VersionNumber: "0.5.0",
+			Files:         []modrinth.File{file},
+			GameVersions:  []string{"1.20.1"},
+			Loaders:       []string{"fabric"},
+			Dependencies: []modrinth.Dependency{
+				{ProjectID: "FAPIPROJ", DependencyType: "required"},
+				{ProjectID: "OPTPROJ1", DependencyType: "optional"},
+				{ProjectID: "OPTIFINE", DependencyType: "incompatible"},
+				// only references a version, the project has to be looked up
+				{VersionID: "LIBVER01", DependencyType: "required"},
+			},
+		},
+		"SODIUMFG": {
+			ID:            "SODIUMFG",
+			ProjectID:     "SODIUMPR",
+			VersionNumber: "0.5.0-forge",
+			Files:         []modrinth.File{file},
+			GameVersions:  []string{"1.20.1"},
+			Loaders:       []string{"forge"},
+		},
+		"SODIUM19": {
+			ID:            "SODIUM19",
+			ProjectID:     "SODIUMPR",
+			VersionNumber: "0.4.0",
+			Files:         []modrinth.File{file},
+			GameVersions:  []string{"1.19.2"},
+			Loaders:       []string{"fabric"},
+		},
+		"LIBVER01": {ID: "LIBVER01", ProjectID: "LIBPROJ1", VersionNumber: "1.0.0", Files: []modrinth.File{file}},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/project/{id}", func(w http.ResponseWriter, r *http.Request) {
+		project, ok := projects[r.PathValue("id")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(project)
+	})
+	mux.HandleFunc("/v2/project/sodium/version", func(w http.ResponseWriter, r *http.Request) {
+		var loaders []string
+		json.Unmarshal([]byte(r.URL.Query().Get("loaders")), &loaders)
+		matching := make([]modrinth.Version, 0)
+		for _, id := range []string{"SODIUM01", "SODIUMFG"} {
+			if containsString(loaders, versions[id].Loaders[0]) {
+				matching = append(matching, versions[id])
+			}
+		}
+		json.NewEncoder(w).Encode(matching)
+	})
+	mux.HandleFunc("/v2/version/{id}", func(w http.ResponseWriter, r *http.Request) {
+		version, ok := versions[r.PathValue("id")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(version)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
 func TestModrinthProvider_Resolve(t *testing.T) {
-	provider := ModrinthProvider{modrinth.New(nil)}
+	provider := ModrinthProvider{Client: modrinth.New(nil)}
 	res, err := provider.Resolve(context.Background(), &Request{
 		Dependency: &pkgid.ID{
 			Name:    "fabric-api",
@@ -22,3 +108,84 @@ func TestModrinthProvider_Resolve(t *testing.T) {
 
 	t.Log(res.Lock().Name)
 }
+
+func newTestModrinthProvider(t *testing.T) *ModrinthProvider {
+	server := newModrinthTestServer(t)
+	provider := &ModrinthProvider{Client: modrinth.New(nil)}
+	if err := provider.Client.SetBaseURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestModrinthProvider_Resolve_testServer(t *testing.T) {
+	tests := []struct {
+		name                  string
+		version               string
+		requirements          manifest.PlatformLock
+		wantVersion           string
+		wantDeps              []string
+		wantIncompatibilities []string
+	}{
+		{"latest for fabric", "latest", &manifest.FabricLock{Minecraft: "1.20.1"}, "SODIUM01", []string{"fabric-api@latest", "some-lib@LIBVER01"}, []string{"optifine@latest"}},
+		{"latest for quilt falls back to fabric", "*", &manifest.QuiltLock{Minecraft: "1.20.1"}, "SODIUM01", []string{"fabric-api@latest", "some-lib@LIBVER01"}, []string{"optifine@latest"}},
+		{"latest for forge", "latest", &manifest.ForgeLock{Minecraft: "1.20.1"}, "SODIUMFG", nil, nil},
+		{"pinned version", "SODIUM01", &manifest.FabricLock{Minecraft: "1.20.1"}, "SODIUM01", []string{"fabric-api@latest", "some-lib@LIBVER01"}, []string{"optifine@latest"}},
+		{"pinned version for quilt", "SODIUM01", &manifest.QuiltLock{Minecraft: "1.20.1"}, "SODIUM01", []string{"fabric-api@latest", "some-lib@LIBVER01"}, []string{"optifine@latest"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestModrinthProvider(t)
+			res, err := provider.Resolve(context.Background(), &Request{
+				Dependency:   &pkgid.ID{Provider: "modrinth", Name: "sodium", Version: tt.version},
+				Requirements: tt.requirements,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lock := res.Lock()
+			if lock.Name != "sodium" || lock.Version != tt.wantVersion || lock.Sha1 != "abc" {
+				t.Errorf("got %s@%s (sha1 %q), want sodium@%s (sha1 \"abc\")", lock.Name, lock.Version, lock.Sha1, tt.wantVersion)
+			}
+			assertModrinthDependencies(t, "dependencies", res.Dependencies(), tt.wantDeps)
+			assertModrinthDependencies(t, "incompatibilities", res.(IncompatibleResult).Incompatibilities(), tt.wantIncompatibilities)
+		})
+	}
+}
+
+func assertModrinthDependencies(t *testing.T, kind string, got []*manifest.InterpretedDependency, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d %s, want %v", len(got), kind, want)
+	}
+	for i, dependency := range got {
+		if id := dependency.Name + "@" + dependency.Source; id != want[i] {
+			t.Errorf("got %s %s, want %s", kind, id, want[i])
+		}
+	}
+}
+
+func TestModrinthProvider_Resolve_pinnedMismatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		version      string
+		requirements manifest.PlatformLock
+	}{
+		{"wrong loader", "SODIUMFG", &manifest.FabricLock{Minecraft: "1.20.1"}},
+		{"wrong minecraft version", "SODIUM19", &manifest.FabricLock{Minecraft: "1.20.1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestModrinthProvider(t)
+			_, err := provider.Resolve(context.Background(), &Request{
+				Dependency:   &pkgid.ID{Provider: "modrinth", Name: "sodium", Version: tt.version},
+				Requirements: tt.requirements,
+			})
+			var mismatch *ErrModrinthVersionMismatch
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("expected ErrModrinthVersionMismatch, got %v", err)
+			}
+		})
+	}
+}
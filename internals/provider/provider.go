@@ -31,6 +31,11 @@ type Request struct {
 	DependencyLock *manifest.DependencyLock
 	// Root is the root dependency lock of the current instance
 	Root *manifest.DependencyLock
+	// MavenRepositories are additional maven repositories requested by the manifest. Can also be nil.
+	MavenRepositories []string
+	// Dir is the directory of the root manifest. Relative `file:` paths are resolved from there.
+	// The working directory is used if empty
+	Dir string
 }
 
 // Result is a result of a dependency resolve
@@ -40,3 +45,28 @@ type Result interface {
 	// Dependencies returns the dependencies of the resolved dependency, can be nil
 	Dependencies() []*manifest.InterpretedDependency
 }
+
+// IncompatibleResult can be implemented by a Result that declares packages it does not work with
+type IncompatibleResult interface {
+	// Incompatibilities returns the packages (and version ranges) that can not be installed
+	// alongside the resolved dependency, can be nil
+	Incompatibilities() []*manifest.InterpretedDependency
+}
+
+// compatibleLoaders returns the loaders whose releases work on the given platform, the preferred loader first.
+// Quilt loads most fabric mods, so fabric releases are used if there is no quilt release
+func compatibleLoaders(platform string) []string {
+	if platform == manifest.PlatformQuilt {
+		return []string{manifest.PlatformQuilt, manifest.PlatformFabric}
+	}
+	return []string{platform}
+}
+
+func containsString(list []string, entry string) bool {
+	for _, existing := range list {
+		if existing == entry {
+			return true
+		}
+	}
+	return false
+}
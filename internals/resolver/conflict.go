@@ -0,0 +1,157 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+// Requirement is a single constraint that was put on a package
+type Requirement struct {
+	// Requester is the name of the package that wants this dependency. Empty for the root package
+	Requester string
+	// Range is the version range that was requested (eg. "^1.2.0", "latest" or a modrinth version id)
+	Range string
+	// Provider is the provider that was requested for this dependency (eg. "minepkg")
+	Provider string
+	// IsDev is true if this requirement stems from dev.dependencies
+	IsDev bool
+}
+
+// RequesterName returns the name of the requester or "(root)" for the root package
+func (r *Requirement) RequesterName() string {
+	if r.Requester == "" {
+		return "(root)"
+	}
+	return r.Requester
+}
+
+func (r *Requirement) String() string {
+	dev := ""
+	if r.IsDev {
+		dev = " (dev)"
+	}
+	return fmt.Sprintf("%s%s requires %s:%s", r.RequesterName(), dev, r.Provider, r.Range)
+}
+
+// SatisfiedBy returns true if the given lock is a version that matches this requirement
+func (r *Requirement) SatisfiedBy(lock *manifest.DependencyLock) bool {
+	if isAnyVersion(r.Range) {
+		return true
+	}
+	if r.Range == lock.Version || (lock.VersionName != "" && r.Range == lock.VersionName) {
+		return true
+	}
+
+	constraint, err := semver.NewConstraint(r.Range)
+	// not a semver range and not equal
+	if err != nil {
+		return false
+	}
+
+	for _, v := range []string{lock.Version, lock.VersionName} {
+		if v == "" {
+			continue
+		}
+		if version, err := semver.NewVersion(v); err == nil && constraint.Check(version) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ErrVersionConflict is returned if the requirements on a package can not be satisfied by a single version
+type ErrVersionConflict struct {
+	// Package is the name of the package with conflicting requirements
+	Package string
+	// Requirements are all the requirements that were put on this package
+	Requirements []*Requirement
+	// Resolved is the last version that was resolved for this package (if any)
+	Resolved *manifest.DependencyLock
+	// Err is the error that occurred while trying to find a version for all requirements (if any)
+	Err error
+}
+
+func (e *ErrVersionConflict) Error() string {
+	lines := make([]string, 0, len(e.Requirements)+2)
+	lines = append(lines, fmt.Sprintf("No version of %s satisfies all requirements:", e.Package))
+	for _, req := range e.Requirements {
+		lines = append(lines, "\t"+req.String())
+	}
+	if e.Err != nil {
+		lines = append(lines, "\tReason: "+e.Err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *ErrVersionConflict) Unwrap() error {
+	return e.Err
+}
+
+// isAnyVersion returns true if the given range matches every version
+func isAnyVersion(versionRange string) bool {
+	return versionRange == "" || versionRange == "*" || versionRange == "latest"
+}
+
+// mergeRanges combines all requirement ranges into a single range.
+// returns false if the ranges can not be combined (eg. because they contain "||")
+func mergeRanges(requirements []*Requirement) (string, bool) {
+	ranges := make([]string, 0, len(requirements))
+	seen := make(map[string]bool)
+	onlySemver := true
+
+	for _, req := range requirements {
+		if isAnyVersion(req.Range) || seen[req.Range] {
+			continue
+		}
+		seen[req.Range] = true
+		if strings.Contains(req.Range, "||") {
+			return "", false
+		}
+		if _, err := semver.NewConstraint(req.Range); err != nil {
+			onlySemver = false
+		}
+		ranges = append(ranges, req.Range)
+	}
+
+	switch {
+	case len(ranges) == 0:
+		return "*", true
+	case len(ranges) == 1:
+		return ranges[0], true
+	case !onlySemver:
+		// non semver ranges (like modrinth version ids) can not be combined with others
+		return "", false
+	}
+
+	// a space is treated as "and" by the minepkg api and our semver library
+	return strings.Join(ranges, " "), true
+}
+
+// ErrIncompatible is returned if a resolved package is declared as incompatible by another resolved package
+type ErrIncompatible struct {
+	// Package is the name of the package that declares the incompatibility
+	Package string
+	// Incompatible is the resolved package that Package does not work with
+	Incompatible *manifest.DependencyLock
+	// Range is the version range that was declared as incompatible
+	Range string
+}
+
+func (e *ErrIncompatible) Error() string {
+	incompatibleRange := ""
+	if !isAnyVersion(e.Range) {
+		incompatibleRange = " " + e.Range
+	}
+	return fmt.Sprintf(
+		"%s is incompatible with %s%s, but %s@%s was resolved",
+		e.Package,
+		e.Incompatible.Name,
+		incompatibleRange,
+		e.Incompatible.Name,
+		e.Incompatible.Version,
+	)
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -55,13 +58,32 @@ type Resolver struct {
 	// IgnoreVersion will make the resolver ignore all version requirements and just fetch the latest version for everything
 	IgnoreVersion bool
 	// IncludeDev includes dev.dependencies
-	IncludeDev   bool
+	IncludeDev bool
+	// AlsoDownload calls Download for every package as soon as it is resolved,
+	// so downloads do not have to wait for the whole graph. Resolve waits for all downloads
 	AlsoDownload bool
+	// Download downloads a resolved package (eg. into the package cache). Required for AlsoDownload
+	Download func(ctx context.Context, lock *manifest.DependencyLock) error
+	// Dir is the directory of the manifest. Relative `file:` dependencies are resolved from there
+	Dir string
 
 	resolvingFinished bool
 	downloadWg        sync.WaitGroup
+	downloadMu        sync.Mutex
+	downloadErr       error
 	subscribers       []chan *Resolved
 	ProviderStore     *provider.Store
+
+	// requirements contains every requirement that was put on a package (by package name)
+	requirements map[string][]*Requirement
+	// results contains the currently resolved result for each package (by package name)
+	results map[string]*Resolved
+	// attempted contains the merged ranges that were already tried for a package
+	attempted map[string]map[string]bool
+	// overrides are the forced dependencies from the manifest (by package name)
+	overrides map[string]*manifest.InterpretedDependency
+	// overridden contains the original requests that were replaced by an override (by package name)
+	overridden map[string][]string
 }
 
 // New returns a new resolver
@@ -73,8 +95,13 @@ func New(man *manifest.Manifest, platformLock manifest.PlatformLock) *Resolver {
 		GlobalReqs:     platformLock,
 		IgnoreVersion:  false,
 		IncludeDev:     true,
-		AlsoDownload:   false, // TODO: set to true when working properly
+		AlsoDownload:   false,
 		downloadWg:     sync.WaitGroup{},
+		requirements:   make(map[string][]*Requirement),
+		results:        make(map[string]*Resolved),
+		attempted:      make(map[string]map[string]bool),
+		overrides:      make(map[string]*manifest.InterpretedDependency),
+		overridden:     make(map[string][]string),
 	}
 
 	return resolver
@@ -88,6 +115,11 @@ func (r *Resolver) ResolveFinished() bool {
 	return r.resolvingFinished
 }
 
+// Requirements returns all requirements that were put on the given package
+func (r *Resolver) Requirements(name string) []*Requirement {
+	return r.requirements[name]
+}
+
 func (r *Resolver) Subscribe() chan *Resolved {
 	subChannel := make(chan *Resolved)
 	r.subscribers = append(r.subscribers, subChannel)
@@ -116,25 +148,62 @@ func (r *Resolver) Resolve(ctx context.Context) error {
 		return ErrNoGlobalReqs
 	}
 
+	for _, override := range man.InterpretedOverrides() {
+		r.overrides[override.Name] = override
+	}
+
 	if err := r.ResolveDependencies(ctx, man.InterpretedDependencies(), false); err != nil {
 		return err
 	}
 
 	if r.IncludeDev {
-		if err := r.ResolveDependencies(ctx, man.InterpretedDevDependencies(), false); err != nil {
+		if err := r.ResolveDependencies(ctx, man.InterpretedDevDependencies(), true); err != nil {
 			return err
 		}
 	}
 
+	// versions that got replaced might have left some dependencies behind
+	r.prune()
+	r.annotateOverrides()
+	r.annotateDependents()
+
+	if err := r.checkIncompatibilities(); err != nil {
+		return err
+	}
+
 	r.resolvingFinished = true
 
 	if r.AlsoDownload {
 		r.downloadWg.Wait()
+		return r.downloadErr
 	}
 
 	return nil
 }
 
+// startDownload downloads the resolved package in the background if AlsoDownload is set.
+// Packages that get replaced by another version later are downloaded anyway
+func (r *Resolver) startDownload(ctx context.Context, resolved *Resolved) {
+	if !r.AlsoDownload || r.Download == nil {
+		return
+	}
+
+	lock := resolved.Lock()
+	lock.Name = resolved.Key
+
+	r.downloadWg.Add(1)
+	go func() {
+		defer r.downloadWg.Done()
+		if err := r.Download(ctx, lock); err != nil {
+			r.downloadMu.Lock()
+			defer r.downloadMu.Unlock()
+			if r.downloadErr == nil {
+				r.downloadErr = fmt.Errorf("failed to download %s: %w", lock.Name, err)
+			}
+		}
+	}()
+}
+
 // Resolve resolves all given dependencies
 func (r *Resolver) ResolveDependencies(ctx context.Context, dependencies []*manifest.InterpretedDependency, isDev bool) error {
 
@@ -142,67 +211,478 @@ func (r *Resolver) ResolveDependencies(ctx context.Context, dependencies []*mani
 	resultsC := make(chan *Resolved)
 	errorC := make(chan error)
 
+	// stops the resolves that are still in flight once we return (on the first error)
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+
 	queryQueue := make(chan interface{}, 24) // 24 is good
 
-	asyncResolve := func(dependency *manifest.InterpretedDependency, root *manifest.DependencyLock) {
-		queryQueue <- nil
-		// t := time.Now()
-		ctx, cancel := context.WithTimeout(ctx, time.Minute*2)
+	asyncResolve := func(dependency *manifest.InterpretedDependency, root *manifest.DependencyLock, conflict *ErrVersionConflict) {
+		select {
+		case queryQueue <- nil:
+		case <-workerCtx.Done():
+			return
+		}
+		defer func() { <-queryQueue }()
+
+		ctx, cancel := context.WithTimeout(workerCtx, time.Minute*2)
 		defer cancel()
 
 		result, err := r.resolveSingle(ctx, dependency, root)
 		if err != nil {
-			errorC <- err
+			// this was an attempt to find a version for conflicting requirements
+			if conflict != nil {
+				conflict.Err = err
+				err = conflict
+			}
+			select {
+			case errorC <- err:
+			case <-workerCtx.Done():
+			}
 			return
 		}
 
-		resultsC <- result
-		<-queryQueue
+		select {
+		case resultsC <- result:
+		case <-workerCtx.Done():
+		}
+	}
+
+	// number of resolves in flight for a package. only the last one counts
+	pending := make(map[string]int)
+
+	startResolve := func(dependency *manifest.InterpretedDependency, root *manifest.DependencyLock, conflict *ErrVersionConflict) {
+		resolving++
+		pending[dependency.Name]++
+		if _, ok := r.Resolved[dependency.Name]; !ok {
+			r.Resolved[dependency.Name] = nil
+		}
+		go asyncResolve(dependency, root, conflict)
 	}
 
-	batchResolve := func(dependencies []*manifest.InterpretedDependency, root *manifest.DependencyLock) {
+	batchResolve := func(dependencies []*manifest.InterpretedDependency, requester string, root *manifest.DependencyLock) error {
+		// providers might return dependencies in any order
+		dependencies = sortedDependencies(dependencies)
 		for _, dep := range dependencies {
-			// already resolved
-			// TODO: check if this version is newer
+			dep = r.applyOverride(dep, requester, isDev)
+			r.addRequirement(dep, requester, isDev)
+
 			_, ok := r.Resolved[dep.Name]
-			if ok {
+			if !ok {
+				startResolve(dep, root, nil)
 				continue
 			}
-			resolving++
 
-			r.Resolved[dep.Name] = nil
+			// still resolving, requirements are checked when the result arrives
+			existing := r.results[dep.Name]
+			if existing == nil || pending[dep.Name] != 0 {
+				continue
+			}
 
-			go asyncResolve(dep, root)
+			// already resolved, but the new requirement might need another version
+			retry, err := r.unify(dep.Name)
+			if err != nil {
+				return err
+			}
+			if retry != nil {
+				startResolve(retry.dependency, existing.Request.Root, retry.conflict)
+			}
 		}
+		return nil
 	}
 
-	for {
-		// start resolving the 1st level
-		batchResolve(dependencies, nil)
+	// start resolving the 1st level
+	if err := batchResolve(dependencies, "", nil); err != nil {
+		return err
+	}
 
-		if resolving == 0 {
-			return nil
+	for resolving != 0 {
+		// wait for every resolve that is in flight and handle the results sorted by name.
+		// this makes the result independent of the order in which the providers answer
+		wave := make([]*Resolved, 0, resolving)
+		for resolving != 0 {
+			select {
+			case err := <-errorC:
+				return err
+			case resolved := <-resultsC:
+				resolving--
+				wave = append(wave, resolved)
+			}
 		}
+		sort.SliceStable(wave, func(a, b int) bool { return wave[a].Key < wave[b].Key })
 
-		select {
-		case err := <-errorC:
-			return err
-		case resolved := <-resultsC:
-			resolving--
-			lock := resolved.result.Lock()
+		for _, resolved := range wave {
+			pending[resolved.Key]--
+			// there is a newer resolve for this package on the way
+			if pending[resolved.Key] != 0 {
+				continue
+			}
+
+			// this is a different version than before, the old one might have required other packages
+			previous := r.results[resolved.Key]
+			if previous != nil {
+				r.removeRequirementsBy(resolved.Key)
+			}
+			r.results[resolved.Key] = resolved
+
+			retry, err := r.unify(resolved.Key)
+			if err != nil {
+				return err
+			}
+			// does not satisfy all requirements. we don't need to look at its dependencies
+			if retry != nil {
+				startResolve(retry.dependency, resolved.Request.Root, retry.conflict)
+				continue
+			}
+
+			lock := resolved.Lock()
+			lock.Name = resolved.Key
 
 			if isDev {
 				lock.IsDev = true
 			}
-			r.Resolved[lock.Name] = lock
-			r.BetterResolved = append(r.BetterResolved, resolved)
+			r.Resolved[resolved.Key] = lock
+			r.replaceBetterResolved(previous, resolved)
 
 			r.notifySubscribers(resolved)
+			r.startDownload(ctx, resolved)
 
 			// resolve the dependencies of this package
-			batchResolve(resolved.result.Dependencies(), resolved.result.Lock())
+			if err := batchResolve(resolved.result.Dependencies(), resolved.Key, lock); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sortedDependencies returns a copy of the dependencies sorted by name
+func sortedDependencies(dependencies []*manifest.InterpretedDependency) []*manifest.InterpretedDependency {
+	sorted := make([]*manifest.InterpretedDependency, len(dependencies))
+	copy(sorted, dependencies)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Name < sorted[b].Name })
+	return sorted
+}
+
+// addRequirement records the requirement that the requester puts on the dependency.
+// requester is empty for the root package
+func (r *Resolver) addRequirement(dependency *manifest.InterpretedDependency, requester string, isDev bool) {
+	versionRange := dependency.Source
+	if dependency.ID != nil {
+		versionRange = dependency.ID.Version
+	}
+
+	r.requirements[dependency.Name] = append(r.requirements[dependency.Name], &Requirement{
+		Requester: requester,
+		Range:     versionRange,
+		Provider:  dependency.Provider,
+		IsDev:     isDev,
+	})
+}
+
+// applyOverride replaces the dependency with the forced one from the manifest overrides (if any)
+// and records the original request. Overrides that only contain a version keep the requested provider
+func (r *Resolver) applyOverride(dependency *manifest.InterpretedDependency, requester string, isDev bool) *manifest.InterpretedDependency {
+	override, ok := r.overrides[dependency.Name]
+	if !ok {
+		return dependency
+	}
+
+	original := &Requirement{
+		Requester: requester,
+		Range:     dependency.Source,
+		Provider:  dependency.Provider,
+		IsDev:     isDev,
+	}
+	if dependency.ID != nil {
+		original.Range = dependency.ID.Version
+	}
+	r.overridden[dependency.Name] = appendUnique(r.overridden[dependency.Name], original.String())
+
+	forced := *override
+	if dependency.ID != nil && isVersionOnly(r.manifest.Overrides[dependency.Name]) {
+		id := *dependency.ID
+		id.Version = override.Source
+		forced.ID = &id
+		forced.Provider = id.Provider
+	}
+	forced.IsDev = dependency.IsDev
+
+	return &forced
+}
+
+// annotateOverrides records the overrides and the requests they replaced in the resolved locks
+func (r *Resolver) annotateOverrides() {
+	for name, lock := range r.Resolved {
+		override, ok := r.overrides[name]
+		if !ok || lock == nil {
+			continue
+		}
+		lock.Override = r.manifest.Overrides[override.Name]
+		lock.OriginalRequests = r.overridden[name]
+		sort.Strings(lock.OriginalRequests)
+	}
+}
+
+// annotateDependents records an edge for every package that requires a resolved package in its lock
+func (r *Resolver) annotateDependents() {
+	rootName := r.manifest.Package.Name
+	if rootName == "" {
+		rootName = "_root"
+	}
+
+	for name, lock := range r.Resolved {
+		if lock == nil {
+			continue
+		}
+		edges := make([]*manifest.DependencyEdge, 0, len(r.requirements[name]))
+		seen := make(map[manifest.DependencyEdge]bool)
+		for _, req := range r.requirements[name] {
+			requester := req.Requester
+			if requester == "" {
+				requester = rootName
+			} else if _, ok := r.Resolved[requester]; !ok {
+				// requester was pruned
+				continue
+			}
+			edge := manifest.DependencyEdge{From: requester, Provider: req.Provider, Range: req.Range, IsDev: req.IsDev}
+			if seen[edge] {
+				continue
+			}
+			seen[edge] = true
+			edges = append(edges, &edge)
+		}
+		sort.Slice(edges, func(a, b int) bool {
+			if edges[a].From != edges[b].From {
+				return edges[a].From < edges[b].From
+			}
+			if edges[a].Provider != edges[b].Provider {
+				return edges[a].Provider < edges[b].Provider
+			}
+			return edges[a].Range < edges[b].Range
+		})
+		lock.RequestedBy = edges
+	}
+}
+
+// isVersionOnly returns true if the dependency source does not name a provider (eg. "1.0.0" but not "modrinth:1.0.0")
+func isVersionOnly(source string) bool {
+	return source != "none" && !strings.Contains(source, ":")
+}
+
+func appendUnique(list []string, entry string) []string {
+	for _, existing := range list {
+		if existing == entry {
+			return list
+		}
+	}
+	return append(list, entry)
+}
+
+// removeRequirementsBy removes all requirements that were added by the given package
+func (r *Resolver) removeRequirementsBy(requester string) {
+	for name, requirements := range r.requirements {
+		kept := requirements[:0]
+		for _, req := range requirements {
+			if req.Requester != requester {
+				kept = append(kept, req)
+			}
+		}
+		r.requirements[name] = kept
+	}
+}
+
+// applicableRequirements returns all requirements of a package that can be checked against the
+// resolved version. Requirements for a different provider are ignored, because the
+// first requested provider always wins (this allows overwriting dependencies, for example with "none")
+func (r *Resolver) applicableRequirements(name string, providerName string) []*Requirement {
+	applicable := make([]*Requirement, 0, len(r.requirements[name]))
+	for _, req := range r.requirements[name] {
+		if req.Provider != providerName {
+			log.Printf("ignoring requirement for %s (%s), %s was already chosen as provider", name, req, providerName)
+			continue
+		}
+		applicable = append(applicable, req)
+	}
+	return applicable
+}
+
+// retry is a dependency that has to be resolved again because of conflicting requirements
+type retry struct {
+	dependency *manifest.InterpretedDependency
+	// conflict is returned if resolving the dependency fails
+	conflict *ErrVersionConflict
+}
+
+// unify checks if the currently resolved version of a package satisfies all of its requirements.
+// It returns a retry if another version might satisfy all of them and an error if this is not possible.
+func (r *Resolver) unify(name string) (*retry, error) {
+	resolved := r.results[name]
+	if resolved == nil || r.IgnoreVersion {
+		return nil, nil
+	}
+
+	lock := resolved.result.Lock()
+	requested := resolved.Request.Dependency
+	requirements := r.applicableRequirements(name, requested.Provider)
+
+	satisfied := true
+	for _, req := range requirements {
+		if !req.SatisfiedBy(lock) {
+			satisfied = false
+			break
+		}
+	}
+	if satisfied {
+		return nil, nil
+	}
+
+	conflict := &ErrVersionConflict{
+		Package:      name,
+		Requirements: requirements,
+		Resolved:     lock,
+	}
+
+	merged, ok := mergeRanges(requirements)
+	if !ok {
+		return nil, conflict
+	}
+
+	// we already tried this range, there is no version that satisfies all requirements
+	if r.attempted[name][merged] || merged == requested.Version {
+		return nil, conflict
+	}
+	if r.attempted[name] == nil {
+		r.attempted[name] = make(map[string]bool)
+	}
+	r.attempted[name][merged] = true
+
+	log.Printf("re-resolving %s with combined range %s", name, merged)
+	id := *requested
+	id.Version = merged
+
+	dependency := &manifest.InterpretedDependency{
+		Provider: id.Provider,
+		Name:     name,
+		Source:   merged,
+		ID:       &id,
+	}
+
+	return &retry{dependency, conflict}, nil
+}
+
+// replaceBetterResolved replaces the previous result (if any) with the new one
+func (r *Resolver) replaceBetterResolved(previous *Resolved, resolved *Resolved) {
+	for i, existing := range r.BetterResolved {
+		if existing == previous {
+			r.BetterResolved[i] = resolved
+			return
+		}
+	}
+	r.BetterResolved = append(r.BetterResolved, resolved)
+}
+
+// prune removes packages that are not required by any other resolved package (or the root) anymore.
+// This can happen if a package was resolved again and the new version has different dependencies.
+func (r *Resolver) prune() {
+	reachable := make(map[string]bool)
+	queue := []string{""}
+
+	for len(queue) != 0 {
+		requester := queue[0]
+		queue = queue[1:]
+		for name, requirements := range r.requirements {
+			if reachable[name] {
+				continue
+			}
+			for _, req := range requirements {
+				if req.Requester == requester {
+					reachable[name] = true
+					queue = append(queue, name)
+					break
+				}
+			}
+		}
+	}
+
+	for name := range r.Resolved {
+		if reachable[name] {
+			continue
 		}
+		log.Printf("removing %s, it is no longer required", name)
+		delete(r.Resolved, name)
+		delete(r.results, name)
+	}
+
+	kept := r.BetterResolved[:0]
+	for _, resolved := range r.BetterResolved {
+		if reachable[resolved.Key] {
+			kept = append(kept, resolved)
+		}
+	}
+	r.BetterResolved = kept
+}
+
+// checkIncompatibilities returns an error if any resolved package is incompatible with another resolved package
+func (r *Resolver) checkIncompatibilities() error {
+	// the root manifest can declare incompatibilities too
+	rootName := r.manifest.Package.Name
+	if rootName == "" {
+		rootName = "(root)"
+	}
+	if err := r.checkIncompatibilitiesOf(rootName, r.manifest.InterpretedIncompatibilities()); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(r.results))
+	for name := range r.results {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		result, ok := r.results[name].result.(provider.IncompatibleResult)
+		if !ok {
+			continue
+		}
+
+		if err := r.checkIncompatibilitiesOf(name, result.Incompatibilities()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkIncompatibilitiesOf returns an error if one of the given incompatibilities of a package got resolved
+func (r *Resolver) checkIncompatibilitiesOf(name string, incompatibilities []*manifest.InterpretedDependency) error {
+	sort.Slice(incompatibilities, func(a, b int) bool {
+		return incompatibilities[a].Name < incompatibilities[b].Name
+	})
+
+	for _, incompatible := range incompatibilities {
+		lock := r.Resolved[incompatible.Name]
+		if lock == nil || incompatible.Name == name {
+			continue
+		}
+
+		// versions of different providers can not be compared, so only "any version" applies to them
+		if lock.Provider != incompatible.Provider && !isAnyVersion(incompatible.Source) {
+			continue
+		}
+
+		requirement := &Requirement{Range: incompatible.Source}
+		if requirement.SatisfiedBy(lock) {
+			return &ErrIncompatible{
+				Package:      name,
+				Incompatible: lock,
+				Range:        incompatible.Source,
+			}
+		}
+	}
+
+	return nil
 }
 
 func (r *Resolver) resolveSingle(ctx context.Context, dependency *manifest.InterpretedDependency, root *manifest.DependencyLock) (*Resolved, error) {
@@ -237,8 +717,10 @@ func (r *Resolver) resolveSingle(ctx context.Context, dependency *manifest.Inter
 
 func (r *Resolver) providerRequest(dep *pkgid.ID, root *manifest.DependencyLock) *provider.Request {
 	return &provider.Request{
-		Dependency:   dep,
-		Requirements: r.GlobalReqs,
-		Root:         root,
+		Dependency:        dep,
+		Requirements:      r.GlobalReqs,
+		Root:              root,
+		MavenRepositories: r.manifest.Maven.Repositories,
+		Dir:               r.Dir,
 	}
 }
@@ -0,0 +1,367 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/minepkg/minepkg/internals/provider"
+	"github.com/minepkg/minepkg/pkg/manifest"
+)
+
+// fakeProvider resolves the highest version matching the requested range
+type fakeProvider struct {
+	// packages maps a package name to its versions and their dependencies
+	packages map[string]map[string]manifest.Dependencies
+}
+
+type fakeResult struct {
+	name         string
+	version      string
+	dependencies manifest.Dependencies
+}
+
+func (f *fakeResult) Lock() *manifest.DependencyLock {
+	return &manifest.DependencyLock{Name: f.name, Version: f.version, Provider: "minepkg"}
+}
+
+func (f *fakeResult) Dependencies() []*manifest.InterpretedDependency {
+	man := manifest.New()
+	man.Dependencies = f.dependencies
+	return man.InterpretedDependencies()
+}
+
+func (f *fakeProvider) Name() string { return "minepkg" }
+
+func (f *fakeProvider) Resolve(ctx context.Context, request *provider.Request) (provider.Result, error) {
+	versionRange := request.Dependency.Version
+	if versionRange == "latest" {
+		versionRange = "*"
+	}
+	constraint, err := semver.NewConstraint(versionRange)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *semver.Version
+	for v := range f.packages[request.Dependency.Name] {
+		version := semver.MustParse(v)
+		if constraint.Check(version) && (best == nil || version.GreaterThan(best)) {
+			best = version
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no version of %s matches %s", request.Dependency.Name, versionRange)
+	}
+
+	return &fakeResult{
+		name:         request.Dependency.Name,
+		version:      best.Original(),
+		dependencies: f.packages[request.Dependency.Name][best.Original()],
+	}, nil
+}
+
+func newTestResolver(deps manifest.Dependencies, packages map[string]map[string]manifest.Dependencies) *Resolver {
+	man := manifest.New()
+	man.Package.Name = "test-pack"
+	man.Dependencies = deps
+
+	res := New(man, &manifest.VanillaLock{Minecraft: "1.20.1"})
+	res.ProviderStore = provider.NewStore(map[string]provider.Provider{
+		"minepkg": &fakeProvider{packages},
+	})
+	return res
+}
+
+func TestResolver_unifiesVersions(t *testing.T) {
+	res := newTestResolver(
+		manifest.Dependencies{"lib": "*", "mod-a": "^1.0.0"},
+		map[string]map[string]manifest.Dependencies{
+			"lib": {
+				"1.0.0": nil,
+				"1.5.0": nil,
+				"2.0.0": {"extra": "*"},
+			},
+			"mod-a": {"1.0.0": {"lib": "^1.2.0"}},
+			"extra": {"1.0.0": nil},
+		},
+	)
+
+	if err := res.Resolve(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Resolved["lib"].Version != "1.5.0" {
+		t.Errorf("expected lib to be unified to 1.5.0, got %s", res.Resolved["lib"].Version)
+	}
+	if _, ok := res.Resolved["extra"]; ok {
+		t.Errorf("expected extra to be removed, because lib 1.5.0 does not require it")
+	}
+	if len(res.Requirements("lib")) != 2 {
+		t.Errorf("expected 2 requirements on lib, got %d", len(res.Requirements("lib")))
+	}
+}
+
+func TestResolver_reportsConflicts(t *testing.T) {
+	res := newTestResolver(
+		manifest.Dependencies{"mod-a": "*", "mod-b": "*"},
+		map[string]map[string]manifest.Dependencies{
+			"lib": {
+				"1.0.0": nil,
+				"2.0.0": nil,
+			},
+			"mod-a": {"1.0.0": {"lib": "^1.0.0"}},
+			"mod-b": {"1.0.0": {"lib": "^2.0.0"}},
+		},
+	)
+
+	err := res.Resolve(context.Background())
+	var conflict *ErrVersionConflict
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+
+	if conflict.Package != "lib" {
+		t.Errorf("expected conflict for lib, got %s", conflict.Package)
+	}
+	if len(conflict.Requirements) != 2 {
+		t.Errorf("expected 2 conflicting requirements, got %d", len(conflict.Requirements))
+	}
+}
+
+func TestMergeRanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranges []string
+		want   string
+		wantOk bool
+	}{
+		{"only wildcards", []string{"*", "latest"}, "*", true},
+		{"single range", []string{"*", "^1.0.0"}, "^1.0.0", true},
+		{"semver ranges", []string{"^1.0.0", ">=1.2.0", "^1.0.0"}, "^1.0.0 >=1.2.0", true},
+		{"or ranges", []string{"^1.0.0 || ^2.0.0", ">=1.2.0"}, "", false},
+		{"version ids", []string{"AABBCCDD", "EEFFGGHH"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requirements := make([]*Requirement, len(tt.ranges))
+			for i, r := range tt.ranges {
+				requirements[i] = &Requirement{Range: r}
+			}
+			got, ok := mergeRanges(requirements)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("mergeRanges() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+// incompatibleResult is a fakeResult that declares incompatible packages
+type incompatibleResult struct {
+	fakeResult
+	incompatibilities manifest.Dependencies
+}
+
+func (i *incompatibleResult) Incompatibilities() []*manifest.InterpretedDependency {
+	man := manifest.New()
+	man.Dependencies = i.incompatibilities
+	return man.InterpretedDependencies()
+}
+
+type incompatibleProvider struct {
+	fakeProvider
+	incompatibilities map[string]manifest.Dependencies
+}
+
+func (i *incompatibleProvider) Resolve(ctx context.Context, request *provider.Request) (provider.Result, error) {
+	result, err := i.fakeProvider.Resolve(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &incompatibleResult{
+		fakeResult:        *result.(*fakeResult),
+		incompatibilities: i.incompatibilities[request.Dependency.Name],
+	}, nil
+}
+
+func TestResolver_reportsIncompatibilities(t *testing.T) {
+	res := newTestResolver(manifest.Dependencies{"mod-a": "*", "mod-b": "*"}, nil)
+	res.ProviderStore = provider.NewStore(map[string]provider.Provider{
+		"minepkg": &incompatibleProvider{
+			fakeProvider: fakeProvider{map[string]map[string]manifest.Dependencies{
+				"mod-a": {"1.0.0": nil},
+				"mod-b": {"1.0.0": nil},
+			}},
+			incompatibilities: map[string]manifest.Dependencies{"mod-a": {"mod-b": "<2.0.0"}},
+		},
+	})
+
+	err := res.Resolve(context.Background())
+	var incompatible *ErrIncompatible
+	if !errors.As(err, &incompatible) {
+		t.Fatalf("expected ErrIncompatible, got %v", err)
+	}
+	if incompatible.Package != "mod-a" || incompatible.Incompatible.Name != "mod-b" {
+		t.Errorf("expected mod-a to be incompatible with mod-b, got %s and %s", incompatible.Package, incompatible.Incompatible.Name)
+	}
+}
+
+func TestResolver_enforcesManifestIncompatibilities(t *testing.T) {
+	res := newTestResolver(
+		manifest.Dependencies{"mod-a": "*"},
+		map[string]map[string]manifest.Dependencies{
+			"mod-a": {"1.0.0": {"lib": "*"}},
+			"lib":   {"1.0.0": nil},
+		},
+	)
+	res.manifest.Incompatibilities = manifest.Dependencies{"lib": "<2.0.0"}
+
+	err := res.Resolve(context.Background())
+	var incompatible *ErrIncompatible
+	if !errors.As(err, &incompatible) {
+		t.Fatalf("expected ErrIncompatible, got %v", err)
+	}
+	if incompatible.Package != "test-pack" || incompatible.Incompatible.Name != "lib" {
+		t.Errorf("expected test-pack to be incompatible with lib, got %s and %s", incompatible.Package, incompatible.Incompatible.Name)
+	}
+}
+
+func TestResolver_appliesOverrides(t *testing.T) {
+	res := newTestResolver(
+		manifest.Dependencies{"mod-a": "*", "mod-b": "*"},
+		map[string]map[string]manifest.Dependencies{
+			"mod-a": {"1.0.0": {"lib": "^2.0.0"}},
+			"mod-b": {"1.0.0": {"lib": "^1.0.0"}},
+			"lib":   {"1.0.0": nil, "1.5.0": nil, "2.0.0": nil},
+		},
+	)
+	res.manifest.Overrides = manifest.Dependencies{"lib": "1.5.0"}
+
+	if err := res.Resolve(context.Background()); err != nil {
+		t.Fatalf("expected overrides to prevent the conflict, got %v", err)
+	}
+
+	lib := res.Resolved["lib"]
+	if lib.Version != "1.5.0" {
+		t.Errorf("expected lib@1.5.0, got %s", lib.Version)
+	}
+	if lib.Override != "1.5.0" {
+		t.Errorf("expected override 1.5.0 to be recorded, got %q", lib.Override)
+	}
+	expected := []string{"mod-a requires minepkg:^2.0.0", "mod-b requires minepkg:^1.0.0"}
+	if fmt.Sprint(lib.OriginalRequests) != fmt.Sprint(expected) {
+		t.Errorf("expected original requests %v, got %v", expected, lib.OriginalRequests)
+	}
+	if len(lib.RequestedBy) != 2 || lib.RequestedBy[0].From != "mod-a" || lib.RequestedBy[1].From != "mod-b" {
+		t.Fatalf("expected edges from mod-a and mod-b, got %v", lib.RequestedBy)
+	}
+	if lib.RequestedBy[0].Range != "1.5.0" {
+		t.Errorf("expected the edge to contain the overridden range 1.5.0, got %q", lib.RequestedBy[0].Range)
+	}
+}
+
+func TestResolver_alsoDownloads(t *testing.T) {
+	res := newTestResolver(
+		manifest.Dependencies{"mod-a": "*"},
+		map[string]map[string]manifest.Dependencies{
+			"mod-a": {"1.0.0": {"lib": "*"}},
+			"lib":   {"1.0.0": nil},
+		},
+	)
+
+	var mu sync.Mutex
+	downloaded := make(map[string]bool)
+	res.AlsoDownload = true
+	res.Download = func(ctx context.Context, lock *manifest.DependencyLock) error {
+		mu.Lock()
+		defer mu.Unlock()
+		downloaded[lock.Name] = true
+		return nil
+	}
+
+	if err := res.Resolve(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !downloaded["mod-a"] || !downloaded["lib"] {
+		t.Errorf("expected mod-a and lib to be downloaded, got %v", downloaded)
+	}
+
+	res = newTestResolver(
+		manifest.Dependencies{"mod-a": "*"},
+		map[string]map[string]manifest.Dependencies{"mod-a": {"1.0.0": nil}},
+	)
+	res.AlsoDownload = true
+	res.Download = func(ctx context.Context, lock *manifest.DependencyLock) error {
+		return errors.New("network is down")
+	}
+	if err := res.Resolve(context.Background()); err == nil {
+		t.Error("expected the download error to be returned")
+	}
+}
+
+func TestResolver_stopsWorkersOnError(t *testing.T) {
+	// more failing dependencies than resolves that can run at once
+	deps := manifest.Dependencies{}
+	for i := 0; i < 40; i++ {
+		deps[fmt.Sprintf("missing-%02d", i)] = "*"
+	}
+	res := newTestResolver(deps, map[string]map[string]manifest.Dependencies{})
+
+	before := runtime.NumGoroutine()
+	if err := res.Resolve(context.Background()); err == nil {
+		t.Fatal("expected an error for the missing packages")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected all resolve workers to exit, %d goroutines are left", runtime.NumGoroutine()-before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// shuffledProvider answers after a random delay, so results arrive in random order
+type shuffledProvider struct{ *fakeProvider }
+
+func (s *shuffledProvider) Resolve(ctx context.Context, request *provider.Request) (provider.Result, error) {
+	time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond)
+	return s.fakeProvider.Resolve(ctx, request)
+}
+
+func TestResolver_isDeterministic(t *testing.T) {
+	packages := map[string]map[string]manifest.Dependencies{
+		"mod-a": {"1.0.0": {"lib": "*"}},
+		"mod-b": {"1.0.0": {"lib": "^1.0.0"}},
+		"mod-c": {"1.0.0": {"lib": "*"}},
+		"lib":   {"1.0.0": nil, "2.0.0": nil},
+	}
+
+	var first string
+	for n := 0; n < 20; n++ {
+		res := newTestResolver(manifest.Dependencies{"mod-a": "*", "mod-b": "*", "mod-c": "*"}, packages)
+		res.ProviderStore = provider.NewStore(map[string]provider.Provider{
+			"minepkg": &shuffledProvider{&fakeProvider{packages}},
+		})
+		if err := res.Resolve(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		lockfile := manifest.NewLockfile()
+		lockfile.Vanilla = &manifest.VanillaLock{Minecraft: "1.20.1"}
+		lockfile.SetDependencies(res.Resolved)
+		if n == 0 {
+			first = lockfile.String()
+			continue
+		}
+		if got := lockfile.String(); got != first {
+			t.Fatalf("expected every resolve to lock the same, got\n%s\ninstead of\n%s", got, first)
+		}
+	}
+}
@@ -19,3 +19,18 @@ func HumanInteger[N constraints.Integer](input N) string {
 	}
 	return fmt.Sprintf("%v", num)
 }
+
+// HumanBytes returns the size in a human readable format (eg. "12.3 MiB")
+func HumanBytes[N constraints.Integer](input N) string {
+	size := float64(input)
+	units := []string{"B", "KiB", "MiB", "GiB"}
+	unit := 0
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d %s", int64(input), units[unit])
+	}
+	return fmt.Sprintf("%.1f %s", size, units[unit])
+}
@@ -29,3 +29,72 @@ func ExampleManifest_marshal() {
 
 	fmt.Println(manifest.String()) // or manifest.Buffer() to get it as a buffer
 }
+
+// Find out why a package is installed
+func ExampleLockfile_PathsTo() {
+	lockfile := manifest.NewLockfile()
+	requestedBy := func(names ...string) []*manifest.DependencyEdge {
+		edges := make([]*manifest.DependencyEdge, len(names))
+		for i, name := range names {
+			edges[i] = &manifest.DependencyEdge{From: name, Provider: "minepkg", Range: "*"}
+		}
+		return edges
+	}
+	lockfile.AddDependency(&manifest.DependencyLock{Name: "mod-a", Version: "1.0.0", RequestedBy: requestedBy("my-pack")})
+	lockfile.AddDependency(&manifest.DependencyLock{Name: "mod-b", Version: "2.0.0", RequestedBy: requestedBy("my-pack")})
+	lockfile.AddDependency(&manifest.DependencyLock{Name: "lib", Version: "1.5.0", RequestedBy: requestedBy("mod-a", "mod-b")})
+
+	for _, path := range lockfile.PathsTo("my-pack", "lib") {
+		fmt.Println(path)
+	}
+	// Output:
+	// [my-pack mod-a lib]
+	// [my-pack mod-b lib]
+}
+
+// Read a v1 lockfile and upgrade it to the current format
+func ExampleLockfile_Upgrade() {
+	v1 := []byte(`
+lockfileVersion = 1
+
+[dependencies.lib]
+  name = "lib"
+  version = "1.5.0"
+  dependent = "mod-a"
+  dependents = ["mod-a", "mod-b"]
+`)
+	lockfile := manifest.Lockfile{}
+	if err := toml.Unmarshal(v1, &lockfile); err != nil {
+		panic(err)
+	}
+
+	lockfile.Upgrade()
+	lib := lockfile.Dependencies["lib"]
+	fmt.Println(lockfile.LockfileVersion, lib.Provider)
+	for _, edge := range lib.RequestedBy {
+		fmt.Println(edge.From)
+	}
+	// Output:
+	// 2 minepkg
+	// mod-a
+	// mod-b
+}
+
+// Find mods that are shipped by more than one dependency
+func ExampleFindModConflicts() {
+	mods := map[string][]*manifest.ProvidedMod{
+		"sodium":      {{ID: "sodium", Version: "0.5.8"}},
+		"sodium-fork": {{ID: "sodium", Version: "0.5.3"}},
+		"mod-a":       {{ID: "mod-a", Version: "1.0.0"}, {ID: "cloth-config", Version: "11.1.106", Nested: true}},
+		"mod-b":       {{ID: "mod-b", Version: "2.0.0"}, {ID: "cloth-config", Version: "13.0.121", Nested: true}},
+		"mod-c":       {{ID: "mod-c", Version: "1.0.0"}, {ID: "cloth-config", Version: "13.0.121", Nested: true}},
+	}
+
+	for _, conflict := range manifest.FindModConflicts(mods) {
+		keep := conflict.Keep()
+		fmt.Println(conflict.ID, conflict.IsDuplicate(), keep.Dependency, keep.Version, conflict.Drop())
+	}
+	// Output:
+	// cloth-config false mod-b 13.0.121 []
+	// sodium true sodium 0.5.8 [sodium-fork]
+}
@@ -0,0 +1,126 @@
+package manifest
+
+import "sort"
+
+// DependencyNode is a package in the resolved dependency tree of a lockfile
+type DependencyNode struct {
+	Name     string `json:"name"`
+	Version  string `json:"version,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	// Cycle is true if this package already appears above in the same branch.
+	// Its dependencies are not repeated in that case
+	Cycle        bool              `json:"cycle,omitempty"`
+	Dependencies []*DependencyNode `json:"dependencies,omitempty"`
+}
+
+// DependentsOf returns all packages that require the given package.
+// Falls back to the single `Dependent` for lockfiles that do not contain the edges
+func (l *Lockfile) DependentsOf(name string) []string {
+	dep, ok := l.Dependencies[name]
+	if !ok {
+		return nil
+	}
+	if len(dep.RequestedBy) == 0 {
+		return []string{dep.Dependent}
+	}
+
+	dependents := make([]string, 0, len(dep.RequestedBy))
+	for _, edge := range dep.RequestedBy {
+		if !containsString(dependents, edge.From) {
+			dependents = append(dependents, edge.From)
+		}
+	}
+	return dependents
+}
+
+func containsString(list []string, entry string) bool {
+	for _, existing := range list {
+		if existing == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// isRootDependent returns true if the dependent is the root package
+func isRootDependent(dependent string, rootName string) bool {
+	return dependent == "" || dependent == "_root" || dependent == rootName
+}
+
+// Tree returns the resolved dependencies as a tree. The root node is the package with the given name
+func (l *Lockfile) Tree(rootName string) *DependencyNode {
+	children := make(map[string][]string)
+	for name := range l.Dependencies {
+		for _, dependent := range l.DependentsOf(name) {
+			if isRootDependent(dependent, rootName) {
+				dependent = rootName
+			}
+			children[dependent] = append(children[dependent], name)
+		}
+	}
+	for _, names := range children {
+		sort.Strings(names)
+	}
+
+	var build func(name string, branch map[string]bool) *DependencyNode
+	build = func(name string, branch map[string]bool) *DependencyNode {
+		node := &DependencyNode{Name: name}
+		if dep, ok := l.Dependencies[name]; ok {
+			node.Version = dep.Version
+			node.Provider = dep.Provider
+		}
+		if branch[name] {
+			node.Cycle = true
+			return node
+		}
+
+		branch[name] = true
+		for _, child := range children[name] {
+			node.Dependencies = append(node.Dependencies, build(child, branch))
+		}
+		delete(branch, name)
+
+		return node
+	}
+
+	return build(rootName, make(map[string]bool))
+}
+
+// PathsTo returns every path from the root package to the given package.
+// Each path starts with the root package name and ends with the given name
+func (l *Lockfile) PathsTo(rootName string, name string) [][]string {
+	paths := make([][]string, 0)
+
+	var walk func(current string, path []string)
+	walk = func(current string, path []string) {
+		for _, visited := range path {
+			if visited == current {
+				return
+			}
+		}
+		path = append([]string{current}, path...)
+
+		for _, dependent := range l.DependentsOf(current) {
+			if isRootDependent(dependent, rootName) {
+				paths = append(paths, append([]string{rootName}, path...))
+				continue
+			}
+			walk(dependent, path)
+		}
+	}
+
+	if _, ok := l.Dependencies[name]; ok {
+		walk(name, nil)
+	}
+
+	sort.Slice(paths, func(a, b int) bool {
+		for i := 0; i < len(paths[a]) && i < len(paths[b]); i++ {
+			if paths[a][i] != paths[b][i] {
+				return paths[a][i] < paths[b][i]
+			}
+		}
+		return len(paths[a]) < len(paths[b])
+	})
+
+	return paths
+}
@@ -1,6 +1,8 @@
 package manifest
 
 import (
+	"sort"
+
 	"github.com/minepkg/minepkg/internals/pkgid"
 )
 
@@ -22,32 +24,53 @@ type InterpretedDependency struct {
 	ID *pkgid.ID
 }
 
-// InterpretedDependencies returns the dependencies in a `[]*InterpretedDependency` slice.
+// InterpretedDependencies returns the dependencies in a `[]*InterpretedDependency` slice sorted by name.
 // See `InterpretedDependency` for details
 func (m *Manifest) InterpretedDependencies() []*InterpretedDependency {
-	interpreted := make([]*InterpretedDependency, len(m.Dependencies))
+	return interpretDependencies(m.Dependencies)
+}
 
-	i := 0
-	for name, source := range m.Dependencies {
-		interpreted[i] = interpretSingleDependency(name, source)
-		i++
+// InterpretedDevDependencies returns the dev.dependencies in a `[]*InterpretedDependency` slice sorted by name.
+// See `InterpretedDependency` for details
+func (m *Manifest) InterpretedDevDependencies() []*InterpretedDependency {
+	interpreted := interpretDependencies(m.Dev.Dependencies)
+	for _, dependency := range interpreted {
+		dependency.IsDev = true
 	}
-
 	return interpreted
 }
 
-// InterpretedDevDependencies returns the dev.dependencies in a `[]*InterpretedDependency` slice.
+// InterpretedOptionalDependencies returns the optionalDependencies in a `[]*InterpretedDependency` slice.
 // See `InterpretedDependency` for details
-func (m *Manifest) InterpretedDevDependencies() []*InterpretedDependency {
-	interpreted := make([]*InterpretedDependency, len(m.Dev.Dependencies))
+func (m *Manifest) InterpretedOptionalDependencies() []*InterpretedDependency {
+	return interpretDependencies(m.OptionalDependencies)
+}
 
-	i := 0
-	for name, source := range m.Dev.Dependencies {
-		interpreted[i] = interpretSingleDependency(name, source)
-		interpreted[i].IsDev = true
-		i++
+// InterpretedIncompatibilities returns the incompatibilities in a `[]*InterpretedDependency` slice.
+// `Source` is the version range that is incompatible. See `InterpretedDependency` for details
+func (m *Manifest) InterpretedIncompatibilities() []*InterpretedDependency {
+	return interpretDependencies(m.Incompatibilities)
+}
+
+// InterpretedOverrides returns the overrides in a `[]*InterpretedDependency` slice.
+// See `InterpretedDependency` for details
+func (m *Manifest) InterpretedOverrides() []*InterpretedDependency {
+	return interpretDependencies(m.Overrides)
+}
+
+// interpretDependencies interprets all dependencies. The result is sorted by name,
+// so it does not depend on the (random) map order
+func interpretDependencies(dependencies Dependencies) []*InterpretedDependency {
+	names := make([]string, 0, len(dependencies))
+	for name := range dependencies {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
+	interpreted := make([]*InterpretedDependency, 0, len(dependencies))
+	for _, name := range names {
+		interpreted = append(interpreted, interpretSingleDependency(name, dependencies[name]))
+	}
 	return interpreted
 }
 
@@ -6,12 +6,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"strings"
 
 	"github.com/pelletier/go-toml"
 )
 
 // LockfileVersion is the current version of the lockfile template
-const LockfileVersion = 1
+const LockfileVersion = 2
 
 var (
 	// ErrDependencyConflicts is returned when trying to add a dependency that is already present
@@ -22,9 +23,18 @@ var (
 	// DependencyLockTypeModpack describes a modpack dependency
 	DependencyLockTypeModpack = "modpack"
 
-	PlatformFabric  = "fabric"
-	PlatformForge   = "forge"
-	PlatformVanilla = "vanilla"
+	// EnvironmentClient describes a dependency that is only needed on the client
+	EnvironmentClient = "client"
+	// EnvironmentServer describes a dependency that is only needed on the server
+	EnvironmentServer = "server"
+	// EnvironmentBoth can be used in the `[environments]` table of the manifest for dependencies that are needed on both sides
+	EnvironmentBoth = "*"
+
+	PlatformFabric   = "fabric"
+	PlatformQuilt    = "quilt"
+	PlatformNeoForge = "neoforge"
+	PlatformForge    = "forge"
+	PlatformVanilla  = "vanilla"
 )
 
 // PlatformLock describes a queryable platform (fabric, forge)
@@ -56,6 +66,8 @@ func (p *PlatformRequirement) PlatformVersion() string {
 type Lockfile struct {
 	LockfileVersion int                        `toml:"lockfileVersion" json:"lockfileVersion"`
 	Fabric          *FabricLock                `toml:"fabric,omitempty" json:"fabric,omitempty"`
+	Quilt           *QuiltLock                 `toml:"quilt,omitempty" json:"quilt,omitempty"`
+	NeoForge        *NeoForgeLock              `toml:"neoforge,omitempty" json:"neoforge,omitempty"`
 	Forge           *ForgeLock                 `toml:"forge,omitempty" json:"forge,omitempty"`
 	Vanilla         *VanillaLock               `toml:"vanilla,omitempty" json:"vanilla,omitempty"`
 	Dependencies    map[string]*DependencyLock `toml:"dependencies,omitempty" json:"dependencies,omitempty"`
@@ -77,6 +89,36 @@ func (f *FabricLock) MinecraftVersion() string { return f.Minecraft }
 // PlatformVersion returns the fabric mod loader version
 func (f *FabricLock) PlatformVersion() string { return f.FabricLoader }
 
+// QuiltLock describes resolved quilt requirements
+type QuiltLock struct {
+	Minecraft   string `toml:"minecraft" json:"minecraft"`
+	QuiltLoader string `toml:"quiltLoader" json:"quiltLoader"`
+}
+
+// PlatformName returns the string quilt
+func (q *QuiltLock) PlatformName() string { return "quilt" }
+
+// MinecraftVersion returns the minecraft version
+func (q *QuiltLock) MinecraftVersion() string { return q.Minecraft }
+
+// PlatformVersion returns the quilt loader version
+func (q *QuiltLock) PlatformVersion() string { return q.QuiltLoader }
+
+// NeoForgeLock describes resolved NeoForge requirements
+type NeoForgeLock struct {
+	Minecraft      string `toml:"minecraft" json:"minecraft"`
+	NeoForgeLoader string `toml:"neoforgeLoader" json:"neoforgeLoader"`
+}
+
+// PlatformName returns the string neoforge
+func (n *NeoForgeLock) PlatformName() string { return "neoforge" }
+
+// MinecraftVersion returns the minecraft version
+func (n *NeoForgeLock) MinecraftVersion() string { return n.Minecraft }
+
+// PlatformVersion returns the NeoForge version
+func (n *NeoForgeLock) PlatformVersion() string { return n.NeoForgeLoader }
+
 // VanillaLock describes resolved vanilla requirements
 type VanillaLock struct {
 	Minecraft string `toml:"minecraft" json:"minecraft"`
@@ -119,12 +161,44 @@ type DependencyLock struct {
 	Sha256      string `toml:"Sha256,omitempty" json:"Sha256,omitempty"`
 	Sha512      string `toml:"Sha512,omitempty" json:"Sha512,omitempty"`
 	URL         string `toml:"url" json:"url"`
+	// Mirrors are additional URLs of the same file. They are tried in order if URL fails
+	Mirrors []string `toml:"mirrors,omitempty" json:"mirrors,omitempty"`
 	// Provider usually is minepkg but can also be https
 	Provider string `toml:"provider" json:"provider"`
-	// Dependent is the package that requires this mod. can be _root if top package
+	// Dependent is the first package that requires this mod. can be _root if top package.
+	// Only kept for older versions of minepkg, see RequestedBy
 	Dependent string `toml:"dependent" json:"dependent"`
+	// RequestedBy contains an edge for every package that requires this mod (including the root package)
+	RequestedBy []*DependencyEdge `toml:"requestedBy,omitempty" json:"requestedBy,omitempty"`
+	// Size of the file in bytes. 0 if unknown
+	Size int64 `toml:"size,omitempty" json:"size,omitempty"`
+	// Environment is EnvironmentClient or EnvironmentServer if the dependency is only needed on one side.
+	// Empty if it is needed on both sides or unknown
+	Environment string `toml:"environment,omitempty" json:"environment,omitempty"`
 	// IsDev is true if this is a dev dependency
 	IsDev bool `toml:"isDev,omitempty" json:"isDev,omitempty"`
+	// Override is the version (or source) that was forced by the `[overrides]` table of the manifest
+	Override string `toml:"override,omitempty" json:"override,omitempty"`
+	// OriginalRequests are the requests that were replaced by the override (eg. "some-mod requires minepkg:^1.0.0")
+	OriginalRequests []string `toml:"originalRequests,omitempty" json:"originalRequests,omitempty"`
+	// Mods are the fabric mod ids provided by the jar, including mods nested inside it (jar-in-jar).
+	// Empty if this is not a fabric mod
+	Mods []*ProvidedMod `toml:"mods,omitempty" json:"mods,omitempty"`
+
+	// LegacyDependents is only read from v1 lockfiles and replaced by RequestedBy
+	LegacyDependents []string `toml:"dependents,omitempty" json:"-"`
+}
+
+// DependencyEdge is a requirement that a package puts on a dependency
+type DependencyEdge struct {
+	// From is the name of the package that requires the dependency
+	From string `toml:"from" json:"from"`
+	// Provider is the requested provider (eg. "minepkg")
+	Provider string `toml:"provider,omitempty" json:"provider,omitempty"`
+	// Range is the requested version range (eg. "^1.2.0", "latest" or a modrinth version id)
+	Range string `toml:"range,omitempty" json:"range,omitempty"`
+	// IsDev is true if this edge stems from dev.dependencies
+	IsDev bool `toml:"isDev,omitempty" json:"isDev,omitempty"`
 }
 
 // FileExt returns ".jar" for mods and ".zip" for modpacks
@@ -136,6 +210,33 @@ func (d *DependencyLock) FileExt() string {
 	return ending
 }
 
+// HasSource returns true if this dependency can be downloaded (it has a URL, a mirror or an IPFS hash)
+func (d *DependencyLock) HasSource() bool {
+	return d.URL != "" || len(d.Mirrors) != 0 || d.IPFSHash != ""
+}
+
+// Sources returns all URLs this dependency can be downloaded from: the URL, the mirrors and
+// the IPFS hash on the given gateway (eg. "https://ipfs.io/ipfs/") if both are set
+func (d *DependencyLock) Sources(ipfsGateway string) []string {
+	sources := make([]string, 0, len(d.Mirrors)+2)
+	seen := make(map[string]bool, len(d.Mirrors)+2)
+	add := func(source string) {
+		if source != "" && !seen[source] {
+			seen[source] = true
+			sources = append(sources, source)
+		}
+	}
+
+	add(d.URL)
+	for _, mirror := range d.Mirrors {
+		add(mirror)
+	}
+	if d.IPFSHash != "" && ipfsGateway != "" {
+		add(strings.TrimSuffix(ipfsGateway, "/") + "/" + d.IPFSHash)
+	}
+	return sources
+}
+
 // Filename returns the dependency in the "[name]-[version].jar" format
 func (d *DependencyLock) Filename() string {
 	return fmt.Sprintf("%s-%s%s", d.Name, d.Version, d.FileExt())
@@ -146,6 +247,10 @@ func (l *Lockfile) MinecraftVersion() string {
 	switch {
 	case l.Fabric != nil:
 		return l.Fabric.Minecraft
+	case l.Quilt != nil:
+		return l.Quilt.Minecraft
+	case l.NeoForge != nil:
+		return l.NeoForge.Minecraft
 	case l.Forge != nil:
 		return l.Forge.Minecraft
 	case l.Vanilla != nil:
@@ -155,11 +260,15 @@ func (l *Lockfile) MinecraftVersion() string {
 	}
 }
 
-// PlatformLock returns the platform lock object (fabric, forge or vanilla lock)
+// PlatformLock returns the platform lock object (fabric, quilt, neoforge, forge or vanilla lock)
 func (l *Lockfile) PlatformLock() PlatformLock {
 	switch {
 	case l.Fabric != nil:
 		return l.Fabric
+	case l.Quilt != nil:
+		return l.Quilt
+	case l.NeoForge != nil:
+		return l.NeoForge
 	case l.Forge != nil:
 		return l.Forge
 	case l.Vanilla != nil:
@@ -174,6 +283,10 @@ func (l *Lockfile) McManifestName() string {
 	switch {
 	case l.Fabric != nil:
 		return l.Fabric.Minecraft + "-fabric-" + l.Fabric.FabricLoader
+	case l.Quilt != nil:
+		return l.Quilt.Minecraft + "-quilt-" + l.Quilt.QuiltLoader
+	case l.NeoForge != nil:
+		return l.NeoForge.Minecraft + "-neoforge-" + l.NeoForge.NeoForgeLoader
 	case l.Forge != nil:
 		return l.Forge.Minecraft + "-forge-" + l.Forge.ForgeLoader
 	case l.Vanilla != nil:
@@ -185,7 +298,7 @@ func (l *Lockfile) McManifestName() string {
 
 // HasRequirements returns true if lockfile has some requirements
 func (l *Lockfile) HasRequirements() bool {
-	return l.Fabric != nil || l.Forge != nil || l.Vanilla != nil
+	return l.Fabric != nil || l.Quilt != nil || l.NeoForge != nil || l.Forge != nil || l.Vanilla != nil
 }
 
 // Buffer returns the manifest as toml in Buffer form
@@ -211,11 +324,51 @@ func (l *Lockfile) AddDependency(dep *DependencyLock) {
 	l.Dependencies[dep.Name] = dep
 }
 
+// SetDependencies replaces all dependencies with the given ones. nil entries are skipped
+func (l *Lockfile) SetDependencies(dependencies map[string]*DependencyLock) {
+	l.ClearDependencies()
+	for _, dep := range dependencies {
+		if dep != nil {
+			l.AddDependency(dep)
+		}
+	}
+}
+
 // ClearDependencies removes all dependencies
 func (l *Lockfile) ClearDependencies() {
 	l.Dependencies = make(map[string]*DependencyLock)
 }
 
+// Upgrade converts an older lockfile to the current LockfileVersion in place.
+// Returns true if anything was changed
+func (l *Lockfile) Upgrade() bool {
+	if l.LockfileVersion >= LockfileVersion {
+		return false
+	}
+
+	for _, dep := range l.Dependencies {
+		// v1 lockfiles did not always contain the provider
+		if dep.Provider == "" {
+			dep.Provider = "minepkg"
+		}
+
+		dependents := dep.LegacyDependents
+		if len(dependents) == 0 && dep.Dependent != "" {
+			dependents = []string{dep.Dependent}
+		}
+		// the requested ranges were not recorded in v1
+		if len(dep.RequestedBy) == 0 {
+			for _, dependent := range dependents {
+				dep.RequestedBy = append(dep.RequestedBy, &DependencyEdge{From: dependent, IsDev: dep.IsDev})
+			}
+		}
+		dep.LegacyDependents = nil
+	}
+
+	l.LockfileVersion = LockfileVersion
+	return true
+}
+
 // NewLockfile returns a new lockfile
 func NewLockfile() *Lockfile {
 	manifest := Lockfile{LockfileVersion: LockfileVersion, Dependencies: make(map[string]*DependencyLock)}
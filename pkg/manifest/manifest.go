@@ -71,13 +71,20 @@ type Manifest struct {
 		// The Minecraft version is binding and implementers should not install
 		// Mods for non-matching Minecraft versions.
 		// Modpack & Mod Authors are encouraged to use semver to allow a broader install range.
+		// Snapshots can be required exactly (eg. 23w31a), with a pre-release constraint (eg. >=1.20.2-pre1)
+		// or with `snapshot:latest`.
 		// This field is REQUIRED
 		Minecraft string `toml:"minecraft" json:"minecraft"`
 		// FabricLoader is a semver version string describing the required FabricLoader version
-		// Only one of `Forge` or `FabricLoader` may be used
+		// Only one of `Forge`, `FabricLoader` or `QuiltLoader` may be used
 		FabricLoader string `toml:"fabricLoader,omitempty" json:"fabricLoader,omitempty"`
-		// ForgeLoader is the minimum forge version required
-		// no semver here, because forge does not follow semver
+		// QuiltLoader is a semver version string describing the required Quilt loader version
+		// Quilt can also load most fabric mods
+		QuiltLoader string `toml:"quiltLoader,omitempty" json:"quiltLoader,omitempty"`
+		// NeoForgeLoader is a semver version string describing the required NeoForge version (eg. ~21.1.0)
+		NeoForgeLoader string `toml:"neoforgeLoader,omitempty" json:"neoforgeLoader,omitempty"`
+		// ForgeLoader is the required forge version. Forge does not follow semver, so this is
+		// either a constraint (eg. ~47.2.0), an exact version, `recommended` or `latest`
 		ForgeLoader string `toml:"forgeLoader,omitempty" json:"forgeLoader,omitempty"`
 		// MinepkgCompanion is the version of the minepkg companion plugin that is going to be added to modpacks.
 		// This has no effect on other types of packages
@@ -91,6 +98,18 @@ type Manifest struct {
 	// Dependencies lists runtime dependencies of this package
 	// this list can contain mods and modpacks
 	Dependencies `toml:"dependencies" json:"dependencies,omitempty"`
+	// OptionalDependencies lists packages that work well with this package but are not required.
+	// They are not installed automatically, but can be added by users when installing this package
+	OptionalDependencies Dependencies `toml:"optionalDependencies,omitempty" json:"optionalDependencies,omitempty"`
+	// Incompatibilities lists packages (and version ranges) that break this package.
+	// Installing this package alongside them will fail
+	Incompatibilities Dependencies `toml:"incompatibilities,omitempty" json:"incompatibilities,omitempty"`
+	// Overrides force a version (or source) for a package, no matter which package in the
+	// dependency tree requests it. Example: `cloth-config = "11.1.106"`
+	Overrides Dependencies `toml:"overrides,omitempty" json:"overrides,omitempty"`
+	// Environments overrides the side a dependency is needed on. Can be "client", "server" or "*" (both).
+	// Example: `sodium = "client"`
+	Environments map[string]string `toml:"environments,omitempty" json:"environments,omitempty"`
 	// Dev contains development & testing related options
 	Dev struct {
 		// BuildCommand is the command used for building this package (usually "./gradlew build")
@@ -101,12 +120,30 @@ type Manifest struct {
 		// They should never be installed for published packages
 		Dependencies `toml:"dependencies,omitempty" json:"dependencies,omitempty"`
 	} `toml:"dev" json:"dev"`
+	// Maven contains options for `maven:` dependencies
+	Maven struct {
+		// Repositories are maven repository URLs that are searched (in order) before the default ones
+		Repositories []string `toml:"repositories,omitempty" json:"repositories,omitempty"`
+	} `toml:"maven,omitempty" json:"maven,omitempty"`
 }
 
 // Dependencies are the dependencies of a mod or modpack as a map
 type Dependencies map[string]string
 
-// PlatformString returns the required platform as a string (vanilla, fabric or forge)
+// EnvironmentOf returns the side the dependency is needed on. The `[environments]` table
+// takes precedence over the given (locked) environment. Returns "" if it is needed on both sides
+func (m *Manifest) EnvironmentOf(name string, locked string) string {
+	override, ok := m.Environments[name]
+	if !ok {
+		return locked
+	}
+	if override == EnvironmentBoth {
+		return ""
+	}
+	return override
+}
+
+// PlatformString returns the required platform as a string (vanilla, fabric, quilt, neoforge or forge)
 func (m *Manifest) PlatformString() string {
 	if m.Package.Platform == PlatformFabric {
 		return PlatformFabric
@@ -114,6 +151,10 @@ func (m *Manifest) PlatformString() string {
 	switch {
 	case m.Requirements.FabricLoader != "":
 		return "fabric"
+	case m.Requirements.QuiltLoader != "":
+		return "quilt"
+	case m.Requirements.NeoForgeLoader != "":
+		return "neoforge"
 	case m.Requirements.ForgeLoader != "":
 		return "forge"
 	default:
@@ -126,6 +167,10 @@ func (m *Manifest) PlatformVersion() string {
 	switch {
 	case m.Requirements.FabricLoader != "":
 		return m.Requirements.FabricLoader
+	case m.Requirements.QuiltLoader != "":
+		return m.Requirements.QuiltLoader
+	case m.Requirements.NeoForgeLoader != "":
+		return m.Requirements.NeoForgeLoader
 	case m.Requirements.ForgeLoader != "":
 		return m.Requirements.ForgeLoader
 	default:
@@ -0,0 +1,137 @@
+package manifest
+
+import (
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ProvidedMod is a fabric mod id that is loaded from the jar of a dependency
+type ProvidedMod struct {
+	ID      string `toml:"id" json:"id"`
+	Version string `toml:"version" json:"version"`
+	// Nested is true if the mod is bundled inside the jar (jar-in-jar)
+	Nested bool `toml:"nested,omitempty" json:"nested,omitempty"`
+}
+
+// ModProvider is a dependency that contains a mod
+type ModProvider struct {
+	// Dependency is the name of the lock entry
+	Dependency string `json:"dependency"`
+	// Version is the version of the mod (not of the dependency)
+	Version string `json:"version"`
+	// Nested is true if the mod is bundled inside the jar of the dependency
+	Nested bool `json:"nested,omitempty"`
+}
+
+// ModConflict is a mod id that is provided by more than one dependency
+type ModConflict struct {
+	ID string `json:"id"`
+	// Providers are all dependencies that contain the mod. The one the mod loader would pick comes first
+	Providers []*ModProvider `json:"providers"`
+}
+
+// IsDuplicate returns true if more than one dependency ships the mod directly (not nested).
+// The mod loader refuses to start in that case
+func (c *ModConflict) IsDuplicate() bool {
+	direct := 0
+	for _, provider := range c.Providers {
+		if !provider.Nested {
+			direct++
+		}
+	}
+	return direct >= 2
+}
+
+// Keep returns the provider with the newest version of the mod
+func (c *ModConflict) Keep() *ModProvider {
+	return c.Providers[0]
+}
+
+// Drop returns the dependencies that should be removed to resolve the conflict:
+// every one that ships an unused copy of the mod directly. Nested copies can not be dropped on their own
+func (c *ModConflict) Drop() []string {
+	drop := make([]string, 0)
+	for _, provider := range c.Providers[1:] {
+		if !provider.Nested && provider.Dependency != c.Keep().Dependency && !containsString(drop, provider.Dependency) {
+			drop = append(drop, provider.Dependency)
+		}
+	}
+	return drop
+}
+
+// FindModConflicts returns every mod id that is provided by more than one dependency, either directly
+// by multiple jars or bundled via jar-in-jar in different versions.
+// `mods` contains the mods of every dependency by dependency name
+func FindModConflicts(mods map[string][]*ProvidedMod) []*ModConflict {
+	providers := make(map[string][]*ModProvider)
+	for name, provided := range mods {
+		for _, mod := range provided {
+			providers[mod.ID] = append(providers[mod.ID], &ModProvider{Dependency: name, Version: mod.Version, Nested: mod.Nested})
+		}
+	}
+
+	conflicts := make([]*ModConflict, 0)
+	for id, candidates := range providers {
+		conflict := &ModConflict{ID: id, Providers: candidates}
+		if !conflict.IsDuplicate() && !differentVersions(candidates) {
+			continue
+		}
+		// multiple dependencies shipping the same version is fine, the loader only loads it once
+		if len(dependencyNames(candidates)) < 2 {
+			continue
+		}
+		sort.SliceStable(candidates, func(a, b int) bool {
+			if cmp := compareModVersions(candidates[a].Version, candidates[b].Version); cmp != 0 {
+				return cmp > 0
+			}
+			if candidates[a].Nested != candidates[b].Nested {
+				return !candidates[a].Nested
+			}
+			return candidates[a].Dependency < candidates[b].Dependency
+		})
+		conflicts = append(conflicts, conflict)
+	}
+
+	sort.Slice(conflicts, func(a, b int) bool { return conflicts[a].ID < conflicts[b].ID })
+	return conflicts
+}
+
+func differentVersions(providers []*ModProvider) bool {
+	for _, provider := range providers[1:] {
+		if provider.Version != providers[0].Version {
+			return true
+		}
+	}
+	return false
+}
+
+func dependencyNames(providers []*ModProvider) []string {
+	names := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		if !containsString(names, provider.Dependency) {
+			names = append(names, provider.Dependency)
+		}
+	}
+	return names
+}
+
+// compareModVersions compares two mod versions as semver. Versions that are not valid semver are compared as strings
+func compareModVersions(a string, b string) int {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	switch {
+	case errA == nil && errB == nil:
+		return va.Compare(vb)
+	case errA == nil:
+		return 1
+	case errB == nil:
+		return -1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/minepkg/minepkg/internals/mcversion"
 )
 
 const (
@@ -62,7 +63,7 @@ var (
 	}
 	// ErrNoLoaderRequirement is returned when the manifest does not contain a loader requirement.
 	ErrNoLoaderRequirement = ValidationError{
-		message: "does not contain either forge or fabric loader requirement",
+		message: "does not contain either forge, neoforge, fabric or quilt loader requirement",
 		Path:    "requirements",
 		Level:   ErrorLevelFatal,
 	}
@@ -76,7 +77,8 @@ var (
 
 // helper regexes
 var (
-	validName = regexp.MustCompile(`^[a-z0-9-_]+$`)
+	validName         = regexp.MustCompile(`^[a-z0-9-_]+$`)
+	exactForgeVersion = regexp.MustCompile(`^[0-9]+(\.[0-9]+)+$`)
 )
 
 type Problems []ValidationError
@@ -99,9 +101,8 @@ func validateMinecraftRequirement(mcVersion string) Problems {
 		return problems
 	}
 
-	_, err := semver.NewConstraint(mcVersion)
+	_, err := mcversion.NewConstraint(mcVersion)
 	if err != nil {
-		fmt.Println(err)
 		problems = append(problems, ErrInvalidMinecraftRequirement)
 		return problems
 	}
@@ -132,14 +133,50 @@ func validateFabricLoader(version string) Problems {
 	return problems
 }
 
-func validateForgeLoader(version string) Problems {
+func validateQuiltLoader(version string) Problems {
 	problems := Problems{}
 
 	_, err := semver.NewConstraint(version)
 	if err != nil {
 		problems = append(problems, ValidationError{
-			message: "manifest contains an invalid fabric loader requirement",
-			Path:    "requirements.fabric",
+			message: "manifest contains an invalid quilt loader requirement",
+			Path:    "requirements.quiltLoader",
+			Level:   ErrorLevelFatal,
+		})
+	}
+
+	return problems
+}
+
+func validateNeoForgeLoader(version string) Problems {
+	problems := Problems{}
+
+	_, err := semver.NewConstraint(version)
+	if err != nil {
+		problems = append(problems, ValidationError{
+			message: "manifest contains an invalid neoforge loader requirement",
+			Path:    "requirements.neoforgeLoader",
+			Level:   ErrorLevelFatal,
+		})
+	}
+
+	return problems
+}
+
+func validateForgeLoader(version string) Problems {
+	problems := Problems{}
+
+	// resolved from the forge promotions
+	if version == "latest" || version == "recommended" {
+		return problems
+	}
+
+	// older forge versions have 4 parts (eg. 14.23.5.2859) and can only be required exactly
+	_, err := semver.NewConstraint(version)
+	if err != nil && !exactForgeVersion.MatchString(version) {
+		problems = append(problems, ValidationError{
+			message: "manifest contains an invalid forge loader requirement",
+			Path:    "requirements.forgeLoader",
 			Level:   ErrorLevelFatal,
 		})
 	}
@@ -184,12 +221,107 @@ func (m *Manifest) Validate() Problems {
 	switch {
 	case m.Requirements.FabricLoader != "":
 		problems = append(problems, validateFabricLoader(m.Requirements.FabricLoader)...)
+	case m.Requirements.QuiltLoader != "":
+		problems = append(problems, validateQuiltLoader(m.Requirements.QuiltLoader)...)
+	case m.Requirements.NeoForgeLoader != "":
+		problems = append(problems, validateNeoForgeLoader(m.Requirements.NeoForgeLoader)...)
 	case m.Requirements.ForgeLoader != "":
 		problems = append(problems, validateForgeLoader(m.Requirements.ForgeLoader)...)
 	default:
 		problems = append(problems, ErrNoLoaderRequirement)
 	}
 
-	// TODO: validate other fields (dependencies, dev stuff)
+	// dependencies
+	problems = append(problems, validateDependencies("dependencies", m.Dependencies)...)
+	problems = append(problems, validateDependencies("dev.dependencies", m.Dev.Dependencies)...)
+	problems = append(problems, validateDependencies("optionalDependencies", m.OptionalDependencies)...)
+	problems = append(problems, validateDependencies("overrides", m.Overrides)...)
+	problems = append(problems, validateIncompatibilities(m)...)
+
+	for name, environment := range m.Environments {
+		switch environment {
+		case EnvironmentClient, EnvironmentServer, EnvironmentBoth:
+		default:
+			problems = append(problems, ValidationError{
+				message: fmt.Sprintf("%s has an invalid environment %q (use client, server or *)", name, environment),
+				Path:    "environments." + name,
+				Level:   ErrorLevelFatal,
+			})
+		}
+	}
+
+	for name := range m.OptionalDependencies {
+		if _, ok := m.Dependencies[name]; ok {
+			problems = append(problems, ValidationError{
+				message: fmt.Sprintf("%s is an optional dependency but also a dependency", name),
+				Path:    "optionalDependencies." + name,
+				Level:   ErrorLevelWarn,
+			})
+		}
+	}
+
+	// TODO: validate other fields (dev stuff)
+	return problems
+}
+
+// validateDependencies checks the names and version ranges of minepkg dependencies
+func validateDependencies(path string, dependencies Dependencies) Problems {
+	problems := Problems{}
+
+	for _, dependency := range interpretDependencies(dependencies) {
+		if !validName.MatchString(dependency.Name) {
+			problems = append(problems, ValidationError{
+				message: fmt.Sprintf("dependency name %q is invalid", dependency.Name),
+				Path:    path + "." + dependency.Name,
+				Level:   ErrorLevelWarn,
+			})
+		}
+
+		// only minepkg dependencies are known to use semver
+		if dependency.Provider != "minepkg" || dependency.Source == "latest" {
+			continue
+		}
+		if _, err := semver.NewConstraint(dependency.Source); err != nil {
+			problems = append(problems, ValidationError{
+				message: fmt.Sprintf("%s has an invalid version requirement %q", dependency.Name, dependency.Source),
+				Path:    path + "." + dependency.Name,
+				Level:   ErrorLevelFatal,
+			})
+		}
+	}
+
+	return problems
+}
+
+// validateIncompatibilities checks that incompatibilities are valid ranges and not required at the same time
+func validateIncompatibilities(m *Manifest) Problems {
+	problems := Problems{}
+
+	for _, incompatibility := range m.InterpretedIncompatibilities() {
+		path := "incompatibilities." + incompatibility.Name
+		versionRange := incompatibility.Source
+		if versionRange != "" && versionRange != "*" && versionRange != "latest" {
+			if _, err := semver.NewConstraint(versionRange); err != nil {
+				problems = append(problems, ValidationError{
+					message: fmt.Sprintf("%s has an invalid version range %q", incompatibility.Name, versionRange),
+					Path:    path,
+					Level:   ErrorLevelFatal,
+				})
+				continue
+			}
+		}
+
+		for _, section := range []Dependencies{m.Dependencies, m.Dev.Dependencies, m.OptionalDependencies} {
+			if _, ok := section[incompatibility.Name]; ok {
+				problems = append(problems, ValidationError{
+					message: fmt.Sprintf("%s is listed as incompatible but also as a dependency", incompatibility.Name),
+					Path:    path,
+					Level:   ErrorLevelFatal,
+				})
+				break
+			}
+		}
+	}
+
 	return problems
 }